@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/annotate"
+)
+
+/*  Write an annotated copy of an image, when --annotate names a directory, by:
+ *    1. Turning the result into labeled boxes. Faces, objects, brands,
+ *       celebrities, and OCR and Read lines have rectangles; the results of
+ *       the other tasks cannot be annotated.
+ *    2. Opening the image again: a local file is read from disk and a remote
+ *       image is downloaded.
+ *    3. Drawing the boxes and writing the copy into the directory, named
+ *       after the image with ".annotated" before the extension. When the
+ *       service saw the image as its pixels are stored (stored is true), the
+ *       boxes are turned with it by its EXIF orientation.
+ *  It returns the path of the copy.
+ */
+func writeAnnotated(ctx context.Context, image visionkit.ImageSource, result interface{}, dir string, stored bool) (string, error) {
+	if content, ok := result.(domainContent); ok {
+		result = content.Celebrities
+	}
+	boxes, err := annotate.Boxes(result)
+	if err != nil {
+		return "", err
+	}
+
+	source, err := openImage(ctx, image)
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, annotatedName(image.Name()))
+	if err := annotate.WriteFile(path, source, boxes, annotate.Options{Stored: stored}); err != nil {
+		return "", fmt.Errorf("annotating %v: %w", image.Name(), err)
+	}
+	return path, nil
+}
+
+// uploadedAsStored reports whether the service saw an image as its pixels
+// are stored, without turning it upright by its EXIF orientation: a remote
+// image, or a local one when the client has no Preprocessor. Tiles are always
+// cut from the upright image.
+func uploadedAsStored(client *visionkit.Client, image visionkit.ImageSource, tiled bool) bool {
+	if tiled {
+		return false
+	}
+	_, remote := image.RemoteURL()
+	return remote || client.Preprocessor == nil
+}
+
+// openImage opens a local image, or downloads a remote one.
+func openImage(ctx context.Context, image visionkit.ImageSource) (io.ReadCloser, error) {
+	imageURL, remote := image.RemoteURL()
+	if !remote {
+		return image.Open()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("downloading %v: %v", imageURL, response.Status)
+	}
+	return response.Body, nil
+}
+
+// download returns a remote image as the bytes of a local one, since tiles
+// are cut from the pixels, and a local image as it is.
+func download(ctx context.Context, image visionkit.ImageSource) (visionkit.ImageSource, error) {
+	if _, remote := image.RemoteURL(); !remote {
+		return image, nil
+	}
+	source, err := openImage(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+	data, err := ioutil.ReadAll(source)
+	if err != nil {
+		return nil, err
+	}
+	return visionkit.Bytes(image.Name(), data), nil
+}
+
+// annotatedName turns the name of an image, such as "photos/a.jpg" or
+// "https://example.com/b.png", into a file name such as
+// "photos_a.annotated.jpg". JPEG and PNG images keep their format; the
+// others are written as PNG.
+func annotatedName(name string) string {
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "https://"), "http://")
+	if i := strings.IndexAny(name, "?#"); i >= 0 {
+		name = name[:i]
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	switch ext {
+	case ".jpg", ".jpeg", ".png":
+	default:
+		ext = ".png"
+	}
+
+	base = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		}
+		return '_'
+	}, base)
+	base = strings.TrimLeft(base, "._")
+	if base == "" {
+		base = "image"
+	}
+	return base + ".annotated" + ext
+}
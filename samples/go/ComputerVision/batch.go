@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/output"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/ratelimit"
+)
+
+/*  Analyze many images in one run by:
+ *    1. Collecting the images from the arguments, which can be directories
+ *       (searched recursively), glob patterns, file paths, or URLs, and from
+ *       an optional newline-delimited list of URLs or paths (--list).
+ *    2. Analyzing the images with a bounded pool of workers (--concurrency),
+ *       requesting the visual features given with --features.
+ *    3. Printing each result, or its error, as soon as it completes. With
+ *       --output ndjson each result is written as one line of JSON.
+ *    4. Writing an annotated copy of each image with --annotate.
+ *    5. Printing a summary, with the rate limiter statistics if --rate-limit
+ *       (or the configuration) set a limit.
+ *  The return value is the process exit code: 1 if any image failed.
+ */
+func runBatch(ctx context.Context, args []string) int {
+	var o options
+	flags := flag.NewFlagSet("batch", flag.ExitOnError)
+	o.register(flags)
+	list := flags.String("list", "", "file with one image URL or path per line")
+	flags.Parse(args)
+
+	client, err := o.newClient()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	writer, err := o.resultWriter()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	images, err := client.ExpandSources(flags.Args())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *list != "" {
+		listFile, err := os.Open(*list)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		listed, err := visionkit.ReadImageList(listFile)
+		listFile.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		images = append(images, listed...)
+	}
+	if len(images) == 0 {
+		fmt.Fprintln(os.Stderr, "No images to analyze. Pass directories, globs, paths, or URLs, or use --list.")
+		return 2
+	}
+
+	failed := 0
+	summary := os.Stdout
+	for result := range client.AnalyzeBatch(ctx, images, o.config.Concurrency, o.config.Features) {
+		if result.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "\n[%v/%v] %v\n", result.Index+1, len(images), result.Err)
+		} else if o.annotate != "" {
+			if _, err := writeAnnotated(ctx, result.Image, result.Result, o.annotate, uploadedAsStored(client, result.Image, false)); err != nil {
+				failed++
+				fmt.Fprintf(os.Stderr, "\n[%v/%v] %v\n", result.Index+1, len(images), err)
+			}
+		}
+		if writer != nil {
+			if err := writer.Write(output.NewRecord("analyze", result.Image.Name(), result.Result, result.Err)); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			continue
+		}
+		if result.Err == nil {
+			fmt.Printf("\n[%v/%v] %v\n", result.Index+1, len(images), result.Image.Name())
+			printAnalysis(os.Stdout, whereOf(result.Image), result.Result.(visionkit.Analysis))
+		}
+	}
+	if writer != nil {
+		if err := writer.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		summary = os.Stderr
+	}
+
+	fmt.Fprintf(summary, "\nAnalyzed %v image(s), %v failed.\n", len(images)-failed, failed)
+	if limiter, ok := client.Limiter.(*ratelimit.Limiter); ok {
+		printLimiterStats(summary, limiter.Stats())
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/config"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/output"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/tile"
+)
+
+/*  The subcommands of the tool. Most of them run one task on every image given
+ *  as an argument, for example:
+ *
+ *    ComputerVision describe resources/faces.jpg https://example.com/landmark.jpg
+ *    ComputerVision analyze --features tags,objects --details landmarks photos/
+ *    ComputerVision read --mode handwritten resources/handwritten_text.jpg
+ *
+ *  An argument can be a local path, a URL, a directory (searched recursively),
+ *  a glob pattern, or "-" for standard input.
+ *
+ *  The results are printed as text unless --output selects json, ndjson, or
+ *  csv, in which case they are written with the visionkit/output package.
+ *
+ *  Every command reads its settings from flags, the environment, and a config
+ *  file, in that order of precedence (see the visionkit/config package).
+ */
+
+// command is a subcommand. run parses the arguments after the command name
+// and returns the exit code of the process; a nil run marks the help command.
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string) int
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		imageCommand("describe", "describe images with captions", describeCommand),
+		imageCommand("tag", "tag images", tagCommand),
+		imageCommand("categorize", "categorize images (use --details for celebrities/landmarks)", categorizeCommand),
+		imageCommand("faces", "detect faces", facesCommand),
+		imageCommand("adult", "detect adult or racy content", adultCommand),
+		imageCommand("color", "detect the color scheme", colorCommand),
+		imageCommand("domain", "detect domain-specific content (--model, see the models command)", domainCommand),
+		imageCommand("imagetype", "detect clip art and line drawings", imageTypeCommand),
+		imageCommand("objects", "detect objects", objectsCommand),
+		imageCommand("brands", "detect brands", brandsCommand),
+		imageCommand("read", "recognize text with the batch Read API (--mode printed|handwritten)", readCommand),
+		imageCommand("ocr", "recognize printed text with OCR", ocrCommand),
+		imageCommand("analyze", "run several visual features in one call (--features)", analyzeCommand),
+		{name: "batch", summary: "analyze directories, globs, and URL lists with a worker pool", run: runBatch},
+		{name: "models", summary: "list the domain-specific models of the endpoint", run: runModels},
+		{name: "quickstart", summary: "run every task against the sample images", run: runQuickstart},
+		{name: "help", summary: "show this help"},
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func printUsage(w io.Writer) {
+	program := filepath.Base(os.Args[0])
+	fmt.Fprintf(w, "Usage: %v <command> [flags] <image>...\n\nCommands:\n", program)
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-11v %v\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nAn image is a path, a URL, a directory, a glob pattern, or - for standard input.\n")
+	fmt.Fprintf(w, "Run \"%v <command> --help\" for the flags of a command.\n", program)
+}
+
+// options holds the flags shared by all the commands. Each command only
+// reads the ones that apply to it. The settings also found in the config
+// file are left empty by default, so that they only override it when given.
+type options struct {
+	configPath   string
+	profile      string
+	endpoint     string
+	region       string
+	cloud        string
+	keyFile      string
+	tokenCmd     string
+	timeout      time.Duration
+	readTimeout  time.Duration
+	concurrency  int
+	maxAttempts  int
+	rateLimit    string
+	burst        int
+	cache        string
+	cacheTTL     time.Duration
+	noCache      bool
+	refresh      bool
+	noPreprocess bool
+	language     string
+	details      string
+	features     string
+	mode         string
+	model        string
+	output       string
+	annotate     string
+	tile         int
+	tileOverlap  int
+
+	// config is the resolved configuration, set by newClient.
+	config *config.Config
+}
+
+// register adds the shared flags to a flag set.
+func (o *options) register(flags *flag.FlagSet) {
+	flags.StringVar(&o.configPath, "config", "", "config file (default: $COMPUTERVISION_CONFIG or computervision/config.yaml in the user config directory)")
+	flags.StringVar(&o.profile, "profile", "", "config file profile (default: $COMPUTERVISION_PROFILE or the file's default_profile)")
+	flags.StringVar(&o.endpoint, "endpoint", "", "full endpoint URL, for example https://my-resource.cognitiveservices.azure.com")
+	flags.StringVar(&o.region, "region", "", "Azure region, for example westus, used when no endpoint URL is set")
+	flags.StringVar(&o.cloud, "cloud", "", "Azure cloud of the region: public, usgov, china, or germany")
+	flags.StringVar(&o.keyFile, "key-file", "", "file holding the API key, read again when it changes")
+	flags.StringVar(&o.tokenCmd, "token-command", "", "command printing an Azure AD bearer token, for a custom subdomain endpoint")
+	flags.DurationVar(&o.timeout, "timeout", 0, "time limit of each HTTP request, for example 30s")
+	flags.DurationVar(&o.readTimeout, "read-timeout", 0, "time limit of each read operation (default 2m)")
+	flags.IntVar(&o.concurrency, "concurrency", 0, fmt.Sprintf("number of images batch analyzes at once (default %v)", visionkit.DefaultConcurrency))
+	flags.IntVar(&o.maxAttempts, "max-attempts", 0, fmt.Sprintf("attempts of each call before giving up, 1 for no retries (default %v)", visionkit.DefaultMaxAttempts))
+	flags.StringVar(&o.rateLimit, "rate-limit", "", "requests allowed per second, or per minute with /m, for example 10 or 20/m (default: no limit)")
+	flags.IntVar(&o.burst, "burst", 0, "requests sent at once after a quiet period (default: the rate per second, at least 1)")
+	flags.StringVar(&o.cache, "cache", "", "result cache directory, memory, or off (default: computervision in the user cache directory)")
+	flags.DurationVar(&o.cacheTTL, "cache-ttl", 0, "time a cached result is kept, for example 168h (default 24h)")
+	flags.BoolVar(&o.noCache, "no-cache", false, "neither read nor write the result cache")
+	flags.BoolVar(&o.refresh, "refresh", false, "call the service for every image and replace the cached results")
+	flags.BoolVar(&o.noPreprocess, "no-preprocess", false, "upload local images as they are, without fitting them to the service limits")
+	flags.StringVar(&o.language, "language", "", "output language, for example en, es, ja (default: the service default)")
+	flags.StringVar(&o.details, "details", "", "comma-separated domain details for categories: celebrities, landmarks")
+	flags.StringVar(&o.features, "features", "", "comma-separated visual features for analyze and batch (default: all but Brands)")
+	flags.StringVar(&o.mode, "mode", "printed", "text recognition mode for read: printed or handwritten")
+	flags.StringVar(&o.model, "model", "", "domain model for domain, such as celebrities or landmarks (default: both)")
+	flags.StringVar(&o.annotate, "annotate", "", "directory to write copies of the images with the faces, objects, brands, celebrities, or text lines drawn on")
+	flags.IntVar(&o.tile, "tile", 0, "analyze images in overlapping tiles of this many pixels for objects and ocr (default: whole images)")
+	flags.IntVar(&o.tileOverlap, "tile-overlap", 0, fmt.Sprintf("pixels shared by neighbouring tiles (default %v)", tile.DefaultOverlap))
+	flags.StringVar(&o.output, "output", "text", "output format: text, json, ndjson, csv (tag, faces, objects, brands, ocr, read, analyze), or hocr or alto (ocr, read)")
+}
+
+// settings returns the flag layer of the configuration.
+func (o *options) settings() config.Settings {
+	cache := o.cache
+	if o.noCache {
+		cache = config.CacheOff
+	}
+	preprocess := ""
+	if o.noPreprocess {
+		preprocess = config.PreprocessOff
+	}
+	return config.Settings{
+		Endpoint:     o.endpoint,
+		Region:       o.region,
+		Cloud:        o.cloud,
+		KeyFile:      o.keyFile,
+		TokenCommand: strings.Fields(o.tokenCmd),
+		Language:     o.language,
+		Features:     config.SplitList(o.features),
+		Details:      config.SplitList(o.details),
+		Timeout:      config.Duration(o.timeout),
+		ReadTimeout:  config.Duration(o.readTimeout),
+		Concurrency:  o.concurrency,
+		MaxAttempts:  o.maxAttempts,
+		RateLimit:    o.rateLimit,
+		Burst:        o.burst,
+		Cache:        cache,
+		CacheTTL:     config.Duration(o.cacheTTL),
+		Preprocess:   preprocess,
+	}
+}
+
+// resultWriter returns the writer for the output format, or nil for text.
+func (o *options) resultWriter() (output.Writer, error) {
+	format, err := output.ParseFormat(o.output)
+	if err != nil || format == output.Text {
+		return nil, err
+	}
+	return output.NewWriter(os.Stdout, format)
+}
+
+// tileOptions returns the options of tiled analysis.
+func (o *options) tileOptions() tile.Options {
+	return tile.Options{Size: o.tile, Overlap: o.tileOverlap, Concurrency: o.config.Concurrency}
+}
+
+// imageTask runs one task on one image and returns its result.
+type imageTask func(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, o *options) (interface{}, error)
+
+// imageCommand builds a subcommand that runs task on every image argument.
+func imageCommand(name, summary string, task imageTask) command {
+	return command{name: name, summary: summary, run: func(ctx context.Context, args []string) int {
+		var o options
+		flags := flag.NewFlagSet(name, flag.ExitOnError)
+		o.register(flags)
+		flags.Parse(args)
+
+		client, err := o.newClient()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		writer, err := o.resultWriter()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		images, err := client.ExpandSources(flags.Args())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(images) == 0 {
+			fmt.Fprintf(os.Stderr, "No images given to %v.\n", name)
+			return 2
+		}
+
+		tiled := o.tile > 0 && (name == "objects" || name == "ocr")
+		for _, image := range images {
+			result, err := task(ctx, client, image, &o)
+			stored := uploadedAsStored(client, image, tiled)
+			if writer == nil {
+				fmt.Printf("\nImage: %v\n", image.Name())
+				if report(err) {
+					present(os.Stdout, whereOf(image), result)
+					if o.annotate != "" {
+						if path, err := writeAnnotated(ctx, image, result, o.annotate, stored); report(err) {
+							fmt.Printf("\nAnnotated image: %v\n", path)
+						}
+					}
+				}
+				continue
+			}
+			if report(err) && o.annotate != "" {
+				_, annotateErr := writeAnnotated(ctx, image, result, o.annotate, stored)
+				report(annotateErr)
+			}
+			if err := writer.Write(output.NewRecord(name, image.Name(), result, err)); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
+		if writer != nil {
+			if err := writer.Close(); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
+		if failures > 0 {
+			return 1
+		}
+		return 0
+	}}
+}
+
+/*  List the domain-specific models of the endpoint by:
+ *    1. Calling ListModels, or reading the list from the result cache.
+ *    2. Printing each model with the categories it adds details to, or
+ *       writing the list as one record with --output json or ndjson.
+ *  Any model listed here can be passed to the domain command with --model.
+ */
+func runModels(ctx context.Context, args []string) int {
+	var o options
+	flags := flag.NewFlagSet("models", flag.ExitOnError)
+	o.register(flags)
+	flags.Parse(args)
+
+	client, err := o.newClient()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	writer, err := o.resultWriter()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	models, err := client.ListModels(ctx)
+	if writer == nil {
+		if !report(err) {
+			return 1
+		}
+		printModels(os.Stdout, models)
+		return 0
+	}
+	report(err)
+	if err := writer.Write(output.NewRecord("models", "", models, err)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := writer.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if failures > 0 {
+		return 1
+	}
+	return 0
+}
+
+// whereOf describes an image as local or remote in the output.
+func whereOf(image visionkit.ImageSource) string {
+	if _, ok := image.RemoteURL(); ok {
+		return "remote"
+	}
+	return "local"
+}
+
+// domainContent is the result of the domain command. A model that was not
+// run is left out of the JSON output.
+type domainContent struct {
+	Celebrities []visionkit.Celebrity `json:"celebrities,omitempty"`
+	Landmarks   []visionkit.Landmark  `json:"landmarks,omitempty"`
+}
+
+func describeCommand(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, o *options) (interface{}, error) {
+	return client.Describe(ctx, image)
+}
+
+func tagCommand(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, o *options) (interface{}, error) {
+	return client.Tag(ctx, image)
+}
+
+func categorizeCommand(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, o *options) (interface{}, error) {
+	return client.Categorize(ctx, image)
+}
+
+func facesCommand(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, o *options) (interface{}, error) {
+	return client.DetectFaces(ctx, image)
+}
+
+func adultCommand(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, o *options) (interface{}, error) {
+	return client.DetectAdultOrRacyContent(ctx, image)
+}
+
+func colorCommand(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, o *options) (interface{}, error) {
+	return client.DetectColorScheme(ctx, image)
+}
+
+func domainCommand(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, o *options) (interface{}, error) {
+	model := o.model
+	if model != "" {
+		domainModel, err := client.FindModel(ctx, model)
+		if err != nil {
+			return nil, err
+		}
+		model = domainModel.Name
+	}
+	switch model {
+	case "", visionkit.DomainCelebrities, visionkit.DomainLandmarks:
+	default:
+		return client.AnalyzeByDomain(ctx, model, image)
+	}
+
+	var content domainContent
+	if model == "" || model == visionkit.DomainCelebrities {
+		celebrities, err := client.DetectCelebrities(ctx, image)
+		if err != nil {
+			return nil, err
+		}
+		content.Celebrities = celebrities
+	}
+	if model == "" || model == visionkit.DomainLandmarks {
+		landmarks, err := client.DetectLandmarks(ctx, image)
+		if err != nil {
+			return nil, err
+		}
+		content.Landmarks = landmarks
+	}
+	return content, nil
+}
+
+func imageTypeCommand(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, o *options) (interface{}, error) {
+	return client.DetectImageTypes(ctx, image)
+}
+
+func objectsCommand(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, o *options) (interface{}, error) {
+	if o.tile > 0 {
+		image, err := download(ctx, image)
+		if err != nil {
+			return nil, err
+		}
+		return tile.DetectObjects(ctx, client, image, o.tileOptions())
+	}
+	return client.DetectObjects(ctx, image)
+}
+
+func brandsCommand(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, o *options) (interface{}, error) {
+	return client.DetectBrands(ctx, image)
+}
+
+func readCommand(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, o *options) (interface{}, error) {
+	mode, err := visionkit.ParseTextRecognitionMode(o.mode)
+	if err != nil {
+		return nil, err
+	}
+	return client.ReadText(ctx, image, mode)
+}
+
+func ocrCommand(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, o *options) (interface{}, error) {
+	language := o.config.OCRLanguage
+	if o.tile > 0 {
+		image, err := download(ctx, image)
+		if err != nil {
+			return nil, err
+		}
+		return tile.OCR(ctx, client, image, language, o.tileOptions())
+	}
+	return client.OCR(ctx, image, language)
+}
+
+func analyzeCommand(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, o *options) (interface{}, error) {
+	return client.Analyze(ctx, image, o.config.Features)
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/ratelimit"
+)
+
+/*  The functions in this file display the results returned by the visionkit
+ *  client. They only format values and never call the service, so the same
+ *  output can be produced for any image, and a failed task never reaches them.
+ */
+
+// present displays any result returned by an image command.
+func present(w io.Writer, where string, result interface{}) {
+	switch r := result.(type) {
+	case visionkit.Description:
+		printCaptions(w, where, r)
+	case []visionkit.Category:
+		printCategories(w, where, r)
+	case []visionkit.Tag:
+		printTags(w, where, r)
+	case []visionkit.Face:
+		printFaces(w, where, r)
+	case visionkit.AdultContent:
+		printAdultOrRacyContent(w, where, r)
+	case visionkit.ColorScheme:
+		printColorScheme(w, where, r)
+	case domainContent:
+		if r.Celebrities != nil {
+			printCelebrities(w, r.Celebrities)
+		}
+		if r.Landmarks != nil {
+			printLandmarks(w, r.Landmarks)
+		}
+	case []visionkit.Celebrity:
+		printCelebrities(w, r)
+	case []visionkit.Landmark:
+		printLandmarks(w, r)
+	case visionkit.DomainResult:
+		printDomainResult(w, r)
+	case []visionkit.DomainModel:
+		printModels(w, r)
+	case visionkit.ImageTypes:
+		printImageTypes(w, where, r)
+	case []visionkit.DetectedObject:
+		printObjects(w, where, r)
+	case []visionkit.Brand:
+		printBrands(w, where, r)
+	case visionkit.ReadResult:
+		printReadResult(w, r)
+	case visionkit.OCRResult:
+		printOCRResult(w, r)
+	case visionkit.Analysis:
+		printAnalysis(w, where, r)
+	default:
+		fmt.Fprintf(w, "%+v\n", r)
+	}
+}
+
+// Display the image captions and their confidence values.
+func printCaptions(w io.Writer, where string, description visionkit.Description) {
+	fmt.Fprintf(w, "\nCaptions from the %v image: \n", where)
+	if len(description.Captions) == 0 {
+		fmt.Fprintln(w, "No captions detected.")
+		return
+	}
+	for _, caption := range description.Captions {
+		fmt.Fprintf(w, "'%v' with confidence %.2f%%\n", caption.Text, caption.Confidence*100)
+	}
+}
+
+// Display the image categories and their confidence values.
+func printCategories(w io.Writer, where string, categories []visionkit.Category) {
+	fmt.Fprintf(w, "\nCategories from the %v image: \n", where)
+	if len(categories) == 0 {
+		fmt.Fprintln(w, "No categories detected.")
+		return
+	}
+	for _, category := range categories {
+		fmt.Fprintf(w, "'%v' with confidence %.2f%%\n", category.Name, category.Score*100)
+		for _, celebrity := range category.Celebrities {
+			fmt.Fprintf(w, "  celebrity: '%v' with confidence %.2f%%\n", celebrity.Name, celebrity.Confidence*100)
+		}
+		for _, landmark := range category.Landmarks {
+			fmt.Fprintf(w, "  landmark: '%v' with confidence %.2f%%\n", landmark.Name, landmark.Confidence*100)
+		}
+	}
+}
+
+// Display the image tags and their confidence values.
+func printTags(w io.Writer, where string, tags []visionkit.Tag) {
+	fmt.Fprintf(w, "\nTags in the %v image: \n", where)
+	if len(tags) == 0 {
+		fmt.Fprintln(w, "No tags detected.")
+		return
+	}
+	for _, tag := range tags {
+		fmt.Fprintf(w, "'%v' with confidence %.2f%%\n", tag.Name, tag.Confidence*100)
+	}
+}
+
+// Display the faces and their bounding boxes.
+func printFaces(w io.Writer, where string, faces []visionkit.Face) {
+	fmt.Fprintf(w, "\nDetecting faces in the %v image ...\n", where)
+	if len(faces) == 0 {
+		fmt.Fprintln(w, "No faces detected.")
+		return
+	}
+	for _, face := range faces {
+		fmt.Fprintf(w, "'%v' of age %v at location %v\n", face.Gender, face.Age, face.Rectangle)
+	}
+}
+
+// Display whether the image has adult or racy content.
+func printAdultOrRacyContent(w io.Writer, where string, adultContent visionkit.AdultContent) {
+	fmt.Fprintf(w, "\nAdult or racy content in the %v image: \n", where)
+	fmt.Fprintf(w, "Is adult content: %v with confidence %.2f%%\n", adultContent.IsAdultContent, adultContent.AdultScore*100)
+	fmt.Fprintf(w, "Has racy content: %v with confidence %.2f%%\n", adultContent.IsRacyContent, adultContent.RacyScore*100)
+}
+
+// Display the color scheme of the image.
+func printColorScheme(w io.Writer, where string, colorScheme visionkit.ColorScheme) {
+	fmt.Fprintf(w, "\nColor scheme of the %v image: \n", where)
+	fmt.Fprintf(w, "Is black and white: %v\n", colorScheme.IsBWImg)
+	fmt.Fprintf(w, "Accent color: 0x%v\n", colorScheme.AccentColor)
+	fmt.Fprintf(w, "Dominant background color: %v\n", colorScheme.DominantColorBackground)
+	fmt.Fprintf(w, "Dominant foreground color: %v\n", colorScheme.DominantColorForeground)
+	fmt.Fprintf(w, "Dominant colors: %v\n", strings.Join(colorScheme.DominantColors, ", "))
+}
+
+// Display the celebrities, their confidence values, and their faces.
+func printCelebrities(w io.Writer, celebrities []visionkit.Celebrity) {
+	fmt.Fprintln(w, "\nCelebrities: ")
+	if len(celebrities) == 0 {
+		fmt.Fprintln(w, "No celebrities detected.")
+		return
+	}
+	for _, celebrity := range celebrities {
+		fmt.Fprintf(w, "'%v' with confidence %.2f%% at location %v\n",
+			celebrity.Name, celebrity.Confidence*100, celebrity.Rectangle)
+	}
+}
+
+// Display the landmarks and their confidence values.
+func printLandmarks(w io.Writer, landmarks []visionkit.Landmark) {
+	fmt.Fprintln(w, "\nLandmarks: ")
+	if len(landmarks) == 0 {
+		fmt.Fprintln(w, "No landmarks detected.")
+		return
+	}
+	for _, landmark := range landmarks {
+		fmt.Fprintf(w, "'%v' with confidence %.2f%%\n", landmark.Name, landmark.Confidence*100)
+	}
+}
+
+// Display the domain-specific models and the categories they add details to.
+func printModels(w io.Writer, models []visionkit.DomainModel) {
+	fmt.Fprintln(w, "\nDomain-specific models: ")
+	if len(models) == 0 {
+		fmt.Fprintln(w, "No models listed.")
+		return
+	}
+	for _, model := range models {
+		fmt.Fprintf(w, "%v: %v\n", model.Name, strings.Join(model.Categories, ", "))
+	}
+}
+
+// Display what a domain-specific model other than celebrities and landmarks
+// recognized.
+func printDomainResult(w io.Writer, domainResult visionkit.DomainResult) {
+	fmt.Fprintf(w, "\nResults of the %v model: \n", domainResult.Model)
+	if len(domainResult.Items) == 0 {
+		fmt.Fprintln(w, "Nothing recognized.")
+		return
+	}
+	for _, item := range domainResult.Items {
+		fmt.Fprintf(w, "'%v' with confidence %.2f%%\n", item.Name, item.Confidence*100)
+	}
+}
+
+// Display the clip art and line drawing types of the image.
+func printImageTypes(w io.Writer, where string, imageTypes visionkit.ImageTypes) {
+	fmt.Fprintf(w, "\nImage type of the %v image:\n", where)
+
+	fmt.Fprintln(w, "\nClip art type: ")
+	fmt.Fprintf(w, "Image is %v.\n", imageTypes.ClipArtType)
+
+	fmt.Fprintln(w, "\nLine drawing type: ")
+	if imageTypes.IsLineDrawing {
+		fmt.Fprintln(w, "Image is a line drawing.")
+	} else {
+		fmt.Fprintln(w, "Image is not a line drawing.")
+	}
+}
+
+// Display the objects and their bounding boxes.
+func printObjects(w io.Writer, where string, objects []visionkit.DetectedObject) {
+	fmt.Fprintf(w, "\nDetecting objects in the %v image: \n", where)
+	if len(objects) == 0 {
+		fmt.Fprintln(w, "No objects detected.")
+		return
+	}
+	for _, object := range objects {
+		fmt.Fprintf(w, "'%v' with confidence %.2f%% at location %v\n",
+			strings.Join(object.Path(), " > "), object.Confidence*100, object.Rectangle)
+	}
+
+	// Count the objects under each broader category, when the service gave
+	// any, for example every dog and cat under "animal".
+	if !hasParents(objects) {
+		return
+	}
+	fmt.Fprintln(w, "\nObjects by category:")
+	for _, count := range visionkit.RollUp(objects) {
+		fmt.Fprintf(w, "'%v': %v with mean confidence %.2f%%\n", count.Name, count.Count, count.Confidence*100)
+	}
+}
+
+func hasParents(objects []visionkit.DetectedObject) bool {
+	for _, object := range objects {
+		if len(object.Parents) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Display the brands, confidence values, and their bounding boxes.
+func printBrands(w io.Writer, where string, brands []visionkit.Brand) {
+	fmt.Fprintf(w, "\nDetecting brands in the %v image: \n", where)
+	if len(brands) == 0 {
+		fmt.Fprintln(w, "No brands detected.")
+		return
+	}
+	for _, brand := range brands {
+		fmt.Fprintf(w, "'%v' with confidence %.2f%% at location %v\n",
+			brand.Name, brand.Confidence*100, brand.Rectangle)
+	}
+}
+
+// Display the lines of text recognized by the Read API.
+func printReadResult(w io.Writer, readResult visionkit.ReadResult) {
+	fmt.Fprintln(w)
+	for _, line := range readResult.Lines() {
+		fmt.Fprintln(w, line.Text)
+	}
+}
+
+// Display the text angle and the lines of text recognized by OCR.
+func printOCRResult(w io.Writer, ocrResult visionkit.OCRResult) {
+	fmt.Fprintf(w, "Text angle: %.4f\n", ocrResult.TextAngle)
+
+	for _, line := range ocrResult.Lines() {
+		fmt.Fprintf(w, "\nBounding box: %v\n", line.Rectangle)
+		fmt.Fprintf(w, "Text: %v\n", line.Text())
+	}
+}
+
+// Display every part of a combined analysis that was requested.
+func printAnalysis(w io.Writer, where string, analysis visionkit.Analysis) {
+	if analysis.Description != nil {
+		printCaptions(w, where, *analysis.Description)
+	}
+	if analysis.Categories != nil {
+		printCategories(w, where, analysis.Categories)
+	}
+	if analysis.Tags != nil {
+		printTags(w, where, analysis.Tags)
+	}
+	if analysis.Faces != nil {
+		printFaces(w, where, analysis.Faces)
+	}
+	if analysis.Adult != nil {
+		printAdultOrRacyContent(w, where, *analysis.Adult)
+	}
+	if analysis.Color != nil {
+		printColorScheme(w, where, *analysis.Color)
+	}
+	if analysis.ImageTypes != nil {
+		printImageTypes(w, where, *analysis.ImageTypes)
+	}
+	if analysis.Brands != nil {
+		printBrands(w, where, analysis.Brands)
+	}
+	if analysis.Objects != nil {
+		printObjects(w, where, analysis.Objects)
+	}
+}
+
+// Display how much the rate limiter held the requests back.
+func printLimiterStats(w io.Writer, stats ratelimit.Stats) {
+	fmt.Fprintf(w, "Rate limiter: %v request(s), %v delayed, average wait %v, longest wait %v, longest queue %v.\n",
+		stats.Requests, stats.Delayed, stats.AverageWait().Round(time.Millisecond), stats.MaxWait.Round(time.Millisecond), stats.MaxWaiting)
+}
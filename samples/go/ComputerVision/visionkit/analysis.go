@@ -0,0 +1,118 @@
+package visionkit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+)
+
+// DefaultFeatures are the visual features requested by Analyze when none are
+// given. Objects are detected with a separate DetectObjects call.
+var DefaultFeatures = []computervision.VisualFeatureTypes{
+	computervision.VisualFeatureTypesDescription,
+	computervision.VisualFeatureTypesCategories,
+	computervision.VisualFeatureTypesTags,
+	computervision.VisualFeatureTypesFaces,
+	computervision.VisualFeatureTypesAdult,
+	computervision.VisualFeatureTypesColor,
+	computervision.VisualFeatureTypesImageType,
+	computervision.VisualFeatureTypesObjects,
+}
+
+// Analysis is the result of Analyze. Only the parts for the requested
+// features are set; the others are null in JSON.
+type Analysis struct {
+	Description *Description     `json:"description"`
+	Categories  []Category       `json:"categories"`
+	Tags        []Tag            `json:"tags"`
+	Faces       []Face           `json:"faces"`
+	Adult       *AdultContent    `json:"adult"`
+	Color       *ColorScheme     `json:"color"`
+	ImageTypes  *ImageTypes      `json:"imageTypes"`
+	Brands      []Brand          `json:"brands"`
+	Objects     []DetectedObject `json:"objects"`
+}
+
+// Analyze runs several visual features on an image. All the features except
+// Objects are requested in a single AnalyzeImage (or AnalyzeImageInStream)
+// call; Objects adds a DetectObjects call, sent at the same time. Use NewPlan
+// and RunPlan to add the domain models, Read, or OCR.
+func (c *Client) Analyze(ctx context.Context, image ImageSource, features []computervision.VisualFeatureTypes) (Analysis, error) {
+	if len(features) == 0 {
+		features = DefaultFeatures
+	}
+
+	var capabilities []Capability
+	for _, feature := range features {
+		capability, ok := capabilityOf(feature)
+		if !ok {
+			return Analysis{}, wrapError("analyze", image, fmt.Errorf("%w %q", ErrUnknownCapability, feature))
+		}
+		capabilities = append(capabilities, capability)
+	}
+	plan, err := NewPlan(capabilities...)
+	if err != nil {
+		return Analysis{}, wrapError("analyze", image, err)
+	}
+
+	result, err := c.RunPlan(ctx, image, plan)
+	if err != nil {
+		return Analysis{}, err
+	}
+	return result.Analysis, nil
+}
+
+// analyzeOnce requests the features in one AnalyzeImage call, or takes the
+// result from the cache, and converts the parts of the response that were
+// asked for.
+func (c *Client) analyzeOnce(ctx context.Context, image ImageSource, features []computervision.VisualFeatureTypes) (analysis Analysis, err error) {
+	params := make([]string, len(features))
+	for i, feature := range features {
+		params[i] = strings.ToLower(string(feature))
+	}
+	sort.Strings(params)
+
+	err = c.cached(ctx, "analyze", image, params, &analysis, func(image ImageSource) error {
+		imageAnalysis, err := c.analyzeFeatures(ctx, image, features)
+		if err != nil {
+			return err
+		}
+		analysis = toAnalysis(imageAnalysis, features)
+		return nil
+	})
+	return analysis, err
+}
+
+// toAnalysis converts the parts of an AnalyzeImage response for the given
+// features.
+func toAnalysis(imageAnalysis computervision.ImageAnalysis, features []computervision.VisualFeatureTypes) Analysis {
+	var analysis Analysis
+	for _, feature := range features {
+		switch feature {
+		case computervision.VisualFeatureTypesDescription:
+			description := toDescription(imageAnalysis.Description)
+			analysis.Description = &description
+		case computervision.VisualFeatureTypesCategories:
+			analysis.Categories = toCategories(imageAnalysis.Categories)
+		case computervision.VisualFeatureTypesTags:
+			analysis.Tags = toTags(imageAnalysis.Tags)
+		case computervision.VisualFeatureTypesFaces:
+			analysis.Faces = toFaces(imageAnalysis.Faces)
+		case computervision.VisualFeatureTypesAdult:
+			adult := toAdultContent(imageAnalysis.Adult)
+			analysis.Adult = &adult
+		case computervision.VisualFeatureTypesColor:
+			color := toColorScheme(imageAnalysis.Color)
+			analysis.Color = &color
+		case computervision.VisualFeatureTypesImageType:
+			imageTypes := toImageTypes(imageAnalysis.ImageType)
+			analysis.ImageTypes = &imageTypes
+		case computervision.VisualFeatureTypesBrands:
+			analysis.Brands = toBrands(imageAnalysis.Brands)
+		}
+	}
+	return analysis
+}
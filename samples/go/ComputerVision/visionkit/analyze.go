@@ -0,0 +1,168 @@
+package visionkit
+
+import (
+	"context"
+	"io"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+)
+
+// maxNumberDescriptionCandidates is the number of captions requested when
+// describing an image.
+const maxNumberDescriptionCandidates int32 = 1
+
+// Describe returns the captions and description tags of an image.
+func (c *Client) Describe(ctx context.Context, image ImageSource) (Description, error) {
+	var description Description
+	err := c.cached(ctx, "describe", image, nil, &description, func(image ImageSource) error {
+		imageDescription, err := c.describeImage(ctx, image)
+		description = toDescription(imageDescription.ImageDescriptionDetails)
+		return err
+	})
+	if err != nil {
+		return Description{}, wrapError("describe", image, err)
+	}
+	return description, nil
+}
+
+// Categorize returns the categories of an image. The celebrities and
+// landmarks found by the domain-specific models are added to the categories
+// when the client asks for them in Details.
+func (c *Client) Categorize(ctx context.Context, image ImageSource) ([]Category, error) {
+	analysis, err := c.analyze(ctx, image, computervision.VisualFeatureTypesCategories)
+	if err != nil {
+		return nil, wrapError("categorize", image, err)
+	}
+	return analysis.Categories, nil
+}
+
+// Tag returns the content tags of an image.
+func (c *Client) Tag(ctx context.Context, image ImageSource) ([]Tag, error) {
+	var tags []Tag
+	err := c.cached(ctx, "tag", image, nil, &tags, func(image ImageSource) error {
+		tagResult, err := c.tagImage(ctx, image)
+		tags = toTags(tagResult.Tags)
+		return err
+	})
+	if err != nil {
+		return nil, wrapError("tag", image, err)
+	}
+	return tags, nil
+}
+
+// DetectFaces returns the faces found in an image.
+func (c *Client) DetectFaces(ctx context.Context, image ImageSource) ([]Face, error) {
+	analysis, err := c.analyze(ctx, image, computervision.VisualFeatureTypesFaces)
+	if err != nil {
+		return nil, wrapError("faces", image, err)
+	}
+	return analysis.Faces, nil
+}
+
+// DetectAdultOrRacyContent checks an image for adult or racy content. The
+// result is empty if the service left it out of the response, as the
+// converters of the optional response fields do.
+func (c *Client) DetectAdultOrRacyContent(ctx context.Context, image ImageSource) (AdultContent, error) {
+	analysis, err := c.analyze(ctx, image, computervision.VisualFeatureTypesAdult)
+	if err != nil {
+		return AdultContent{}, wrapError("adult", image, err)
+	}
+	if analysis.Adult == nil {
+		return AdultContent{}, nil
+	}
+	return *analysis.Adult, nil
+}
+
+// DetectColorScheme returns the color scheme of an image, or an empty one
+// if the service left it out of the response.
+func (c *Client) DetectColorScheme(ctx context.Context, image ImageSource) (ColorScheme, error) {
+	analysis, err := c.analyze(ctx, image, computervision.VisualFeatureTypesColor)
+	if err != nil {
+		return ColorScheme{}, wrapError("color", image, err)
+	}
+	if analysis.Color == nil {
+		return ColorScheme{}, nil
+	}
+	return *analysis.Color, nil
+}
+
+// DetectImageTypes returns the clip art and line drawing types of an image,
+// or empty types if the service left them out of the response.
+func (c *Client) DetectImageTypes(ctx context.Context, image ImageSource) (ImageTypes, error) {
+	analysis, err := c.analyze(ctx, image, computervision.VisualFeatureTypesImageType)
+	if err != nil {
+		return ImageTypes{}, wrapError("imagetype", image, err)
+	}
+	if analysis.ImageTypes == nil {
+		return ImageTypes{}, nil
+	}
+	return *analysis.ImageTypes, nil
+}
+
+// DetectBrands returns the brands found in an image.
+func (c *Client) DetectBrands(ctx context.Context, image ImageSource) ([]Brand, error) {
+	analysis, err := c.analyze(ctx, image, computervision.VisualFeatureTypesBrands)
+	if err != nil {
+		return nil, wrapError("brands", image, err)
+	}
+	return analysis.Brands, nil
+}
+
+// describeImage calls DescribeImage for remote images, or
+// DescribeImageInStream for everything else.
+func (c *Client) describeImage(ctx context.Context, image ImageSource) (description computervision.ImageDescription, err error) {
+	maxCandidates := maxNumberDescriptionCandidates
+	err = c.call(ctx, image, func(imageURL computervision.ImageURL) (err error) {
+		description, err = c.BaseClient.DescribeImage(ctx, imageURL, &maxCandidates, c.Language)
+		return err
+	}, func(localImage io.ReadCloser) (err error) {
+		description, err = c.BaseClient.DescribeImageInStream(ctx, localImage, &maxCandidates, c.Language)
+		return err
+	})
+	return description, err
+}
+
+// tagImage calls TagImage for remote images, or TagImageInStream for
+// everything else.
+func (c *Client) tagImage(ctx context.Context, image ImageSource) (tagResult computervision.TagResult, err error) {
+	err = c.call(ctx, image, func(imageURL computervision.ImageURL) (err error) {
+		tagResult, err = c.BaseClient.TagImage(ctx, imageURL, c.Language)
+		return err
+	}, func(localImage io.ReadCloser) (err error) {
+		tagResult, err = c.BaseClient.TagImageInStream(ctx, localImage, c.Language)
+		return err
+	})
+	return tagResult, err
+}
+
+// analyze calls analyzeOnce with a single feature.
+func (c *Client) analyze(ctx context.Context, image ImageSource, feature computervision.VisualFeatureTypes) (Analysis, error) {
+	return c.analyzeOnce(ctx, image, []computervision.VisualFeatureTypes{feature})
+}
+
+// analyzeFeatures calls AnalyzeImage for remote images, or
+// AnalyzeImageInStream for everything else, with the:
+//   - context
+//   - image
+//   - features to extract
+//   - the client's Details
+//   - the client's output Language
+func (c *Client) analyzeFeatures(ctx context.Context, image ImageSource, features []computervision.VisualFeatureTypes) (imageAnalysis computervision.ImageAnalysis, err error) {
+	details := c.Details
+	if details == nil {
+		details = []computervision.Details{}
+	}
+	err = c.call(ctx, image, func(imageURL computervision.ImageURL) (err error) {
+		imageAnalysis, err = c.BaseClient.AnalyzeImage(ctx, imageURL, features, details, c.Language)
+		return err
+	}, func(localImage io.ReadCloser) (err error) {
+		imageAnalysis, err = c.BaseClient.AnalyzeImageInStream(ctx, localImage, features, details, c.Language)
+		return err
+	})
+	return imageAnalysis, err
+}
+
+// remoteImage saves the URL as an ImageURL type for passing to the SDK methods.
+func remoteImage(imageURL string) computervision.ImageURL {
+	return computervision.ImageURL{URL: &imageURL}
+}
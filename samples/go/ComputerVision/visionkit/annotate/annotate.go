@@ -0,0 +1,141 @@
+// Package annotate draws the rectangles found by the visionkit client onto
+// the image they were found in: faces, objects, brands, celebrities, and
+// lines of text. Each box is outlined in the color of its kind and labeled
+// with its name and confidence, so detection quality can be checked by eye.
+//
+//	boxes, err := annotate.Boxes(objects)
+//	...
+//	err = annotate.WriteFile("objects.png", imageFile, boxes, annotate.Options{})
+package annotate
+
+import (
+	"errors"
+	"image"
+	"strconv"
+	"strings"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+)
+
+// ErrUnsupported is returned by Boxes for results that have no rectangles.
+var ErrUnsupported = errors.New("annotate: result has no rectangles to draw")
+
+// Kinds of boxes. Each kind has its own color in DefaultColors.
+const (
+	KindFace      = "face"
+	KindObject    = "object"
+	KindBrand     = "brand"
+	KindCelebrity = "celebrity"
+	KindText      = "text"
+)
+
+// Box is a labeled rectangle, in pixels of the source image.
+type Box struct {
+	Kind  string
+	Label string
+	// Confidence is shown after the label as a percentage when it is above
+	// zero.
+	Confidence float64
+	Rect       image.Rectangle
+}
+
+// Boxes returns the boxes of a result: []visionkit.Face, []DetectedObject,
+// []Brand, []Celebrity, OCRResult, ReadResult, or Analysis (value or
+// pointer), which adds the celebrities of its categories. Any other result
+// returns ErrUnsupported.
+func Boxes(result interface{}) ([]Box, error) {
+	switch r := result.(type) {
+	case []visionkit.Face:
+		return faceBoxes(r), nil
+	case []visionkit.DetectedObject:
+		return objectBoxes(r), nil
+	case []visionkit.Brand:
+		return brandBoxes(r), nil
+	case []visionkit.Celebrity:
+		return celebrityBoxes(r), nil
+	case visionkit.OCRResult:
+		return ocrBoxes(r), nil
+	case visionkit.ReadResult:
+		return readBoxes(r), nil
+	case visionkit.Analysis:
+		return analysisBoxes(r), nil
+	case *visionkit.Analysis:
+		return analysisBoxes(*r), nil
+	}
+	return nil, ErrUnsupported
+}
+
+func analysisBoxes(analysis visionkit.Analysis) []Box {
+	var boxes []Box
+	boxes = append(boxes, faceBoxes(analysis.Faces)...)
+	boxes = append(boxes, objectBoxes(analysis.Objects)...)
+	boxes = append(boxes, brandBoxes(analysis.Brands)...)
+	for _, category := range analysis.Categories {
+		boxes = append(boxes, celebrityBoxes(category.Celebrities)...)
+	}
+	return boxes
+}
+
+func faceBoxes(faces []visionkit.Face) []Box {
+	var boxes []Box
+	for _, face := range faces {
+		label := face.Gender
+		if face.Age > 0 {
+			label += " " + strconv.Itoa(face.Age)
+		}
+		boxes = append(boxes, Box{Kind: KindFace, Label: strings.TrimSpace(label), Rect: face.Rectangle.Image()})
+	}
+	return boxes
+}
+
+func objectBoxes(objects []visionkit.DetectedObject) []Box {
+	var boxes []Box
+	for _, object := range objects {
+		boxes = append(boxes, Box{Kind: KindObject, Label: object.Name, Confidence: object.Confidence, Rect: object.Rectangle.Image()})
+	}
+	return boxes
+}
+
+func brandBoxes(brands []visionkit.Brand) []Box {
+	var boxes []Box
+	for _, brand := range brands {
+		boxes = append(boxes, Box{Kind: KindBrand, Label: brand.Name, Confidence: brand.Confidence, Rect: brand.Rectangle.Image()})
+	}
+	return boxes
+}
+
+func celebrityBoxes(celebrities []visionkit.Celebrity) []Box {
+	var boxes []Box
+	for _, celebrity := range celebrities {
+		boxes = append(boxes, Box{Kind: KindCelebrity, Label: celebrity.Name, Confidence: celebrity.Confidence, Rect: celebrity.Rectangle.Image()})
+	}
+	return boxes
+}
+
+// ocrBoxes returns a box per line, since word boxes crowd out the image.
+func ocrBoxes(ocrResult visionkit.OCRResult) []Box {
+	var boxes []Box
+	for _, line := range ocrResult.Lines() {
+		if rect := line.Rectangle; !rect.Empty() {
+			boxes = append(boxes, Box{Kind: KindText, Label: line.Text(), Rect: rect.Image()})
+		}
+	}
+	return boxes
+}
+
+// readBoxes returns a box per line of the pages measured in pixels. The
+// pages of a PDF are measured in inches and have no image to draw on.
+func readBoxes(readResult visionkit.ReadResult) []Box {
+	var boxes []Box
+	for _, page := range readResult.Pages {
+		if page.Unit != "" && page.Unit != "pixel" {
+			continue
+		}
+		for _, line := range page.Lines {
+			if rect := line.Rectangle; !rect.Empty() {
+				boxes = append(boxes, Box{Kind: KindText, Label: line.Text, Rect: rect.Image()})
+			}
+		}
+	}
+	return boxes
+}
@@ -0,0 +1,243 @@
+package annotate_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/annotate"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/preprocess"
+)
+
+func TestBoxes(t *testing.T) {
+	rect := geometry.Rect{X: 10, Y: 20, W: 30, H: 40}
+	want := image.Rect(10, 20, 40, 60)
+	tests := []struct {
+		name    string
+		result  interface{}
+		want    []annotate.Box
+		wantErr error
+	}{
+		{
+			name:   "faces",
+			result: []visionkit.Face{{Age: 37, Gender: "Male", Rectangle: rect}, {Rectangle: rect}},
+			want:   []annotate.Box{{Kind: annotate.KindFace, Label: "Male 37", Rect: want}, {Kind: annotate.KindFace, Rect: want}},
+		},
+		{
+			name:   "objects",
+			result: []visionkit.DetectedObject{{Name: "dog", Confidence: 0.7, Rectangle: rect}},
+			want:   []annotate.Box{{Kind: annotate.KindObject, Label: "dog", Confidence: 0.7, Rect: want}},
+		},
+		{
+			name: "analysis",
+			result: &visionkit.Analysis{
+				Brands:     []visionkit.Brand{{Name: "Microsoft", Confidence: 0.5, Rectangle: rect}},
+				Categories: []visionkit.Category{{Name: "people_", Celebrities: []visionkit.Celebrity{{Name: "Satya Nadella", Confidence: 0.9, Rectangle: rect}}}},
+			},
+			want: []annotate.Box{
+				{Kind: annotate.KindBrand, Label: "Microsoft", Confidence: 0.5, Rect: want},
+				{Kind: annotate.KindCelebrity, Label: "Satya Nadella", Confidence: 0.9, Rect: want},
+			},
+		},
+		{
+			name: "ocr lines",
+			result: visionkit.OCRResult{Regions: []visionkit.OCRRegion{{Lines: []visionkit.OCRLine{
+				{Rectangle: rect, Words: []visionkit.OCRWord{{Text: "Hello"}, {Text: "world"}}},
+				{Words: []visionkit.OCRWord{{Text: "no box"}}},
+			}}}},
+			want: []annotate.Box{{Kind: annotate.KindText, Label: "Hello world", Rect: want}},
+		},
+		{
+			name: "read pages in pixels",
+			result: visionkit.ReadResult{Pages: []visionkit.ReadPage{
+				{Unit: "pixel", Lines: []visionkit.ReadLine{{Text: "Total", Rectangle: rect}}},
+				{Unit: "inch", Lines: []visionkit.ReadLine{{Text: "Page two", Rectangle: geometry.Rect{X: 1, Y: 1, W: 2, H: 0.5}}}},
+			}},
+			want: []annotate.Box{{Kind: annotate.KindText, Label: "Total", Rect: want}},
+		},
+		{name: "tags", result: []visionkit.Tag{{Name: "dog"}}, wantErr: annotate.ErrUnsupported},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := annotate.Boxes(test.result)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("error = %v, want %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Boxes = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
+
+// white returns a white image of the given size.
+func white(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	return img
+}
+
+// sameColor reports whether two colors are the same once converted to RGBA.
+func sameColor(a, b color.Color) bool {
+	return color.RGBAModel.Convert(a) == color.RGBAModel.Convert(b)
+}
+
+func TestDraw(t *testing.T) {
+	red := color.RGBA{R: 0xff, A: 0xff}
+	img := white(100, 100)
+	boxes := []annotate.Box{
+		{Kind: annotate.KindObject, Rect: image.Rect(20, 20, 60, 60)},
+		{Kind: annotate.KindFace, Rect: image.Rect(70, 70, 200, 200)},
+		{Kind: annotate.KindText, Rect: image.Rect(-50, -50, -10, -10)},
+	}
+	annotate.Draw(img, boxes, annotate.Options{LineWidth: 3, NoLabels: true, Colors: map[string]color.Color{annotate.KindFace: red}})
+
+	tests := []struct {
+		name  string
+		point image.Point
+		want  color.Color
+	}{
+		{"outline", image.Pt(20, 40), annotate.DefaultColors[annotate.KindObject]},
+		{"inside the line width", image.Pt(22, 40), annotate.DefaultColors[annotate.KindObject]},
+		{"inside the box", image.Pt(40, 40), color.White},
+		{"outside the box", image.Pt(10, 10), color.White},
+		{"clipped box in its color", image.Pt(99, 85), red},
+		{"box outside the image", image.Pt(0, 0), color.White},
+	}
+	for _, test := range tests {
+		if got := img.At(test.point.X, test.point.Y); !sameColor(got, test.want) {
+			t.Errorf("%v: pixel at %v = %v, want %v", test.name, test.point, got, test.want)
+		}
+	}
+}
+
+func TestDrawLabel(t *testing.T) {
+	img := white(200, 100)
+	box := annotate.Box{Kind: annotate.KindObject, Label: "dog", Confidence: 0.71, Rect: image.Rect(50, 50, 150, 90)}
+	annotate.Draw(img, []annotate.Box{box}, annotate.Options{})
+
+	// The label sits on the box color just above the top-left corner.
+	labeled := false
+	for x := 50; x < 100; x++ {
+		for y := 30; y < 50; y++ {
+			if sameColor(img.At(x, y), annotate.DefaultColors[annotate.KindObject]) {
+				labeled = true
+			}
+		}
+	}
+	if !labeled {
+		t.Error("no label above the box")
+	}
+}
+
+func TestRender(t *testing.T) {
+	var src bytes.Buffer
+	if err := png.Encode(&src, white(64, 48)); err != nil {
+		t.Fatal(err)
+	}
+	boxes := []annotate.Box{{Kind: annotate.KindBrand, Rect: image.Rect(8, 8, 40, 40)}}
+
+	var dst bytes.Buffer
+	format, err := annotate.Render(&dst, &src, boxes, annotate.Options{NoLabels: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "png" {
+		t.Errorf("format = %q, want png", format)
+	}
+	img, err := png.Decode(&dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds() != image.Rect(0, 0, 64, 48) || !sameColor(img.At(8, 20), annotate.DefaultColors[annotate.KindBrand]) {
+		t.Errorf("rendered %v with %v at the outline, want the box drawn on a 64x48 image", img.Bounds(), img.At(8, 20))
+	}
+
+	if _, err := annotate.Render(&dst, bytes.NewReader([]byte("not an image")), boxes, annotate.Options{}); err == nil {
+		t.Error("Render succeeded with data that is not an image")
+	}
+}
+
+// rotatedJPEG returns a white JPEG image of the given stored size whose EXIF
+// orientation says to turn it by 90 degrees clockwise for display.
+func rotatedJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var stored bytes.Buffer
+	if err := jpeg.Encode(&stored, white(width, height), nil); err != nil {
+		t.Fatal(err)
+	}
+	exif := []byte("Exif\x00\x00II*\x00\x08\x00\x00\x00")
+	ifd := make([]byte, 2+12+4)
+	binary.LittleEndian.PutUint16(ifd, 1)
+	binary.LittleEndian.PutUint16(ifd[2:], 0x0112)
+	binary.LittleEndian.PutUint16(ifd[4:], 3)
+	binary.LittleEndian.PutUint32(ifd[6:], 1)
+	binary.LittleEndian.PutUint16(ifd[10:], preprocess.OrientationRotate90)
+	exif = append(exif, ifd...)
+
+	data := []byte{0xff, 0xd8, 0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(data[4:], uint16(2+len(exif)))
+	data = append(data, exif...)
+	return append(data, stored.Bytes()[2:]...)
+}
+
+func TestWriteFileStored(t *testing.T) {
+	dir, err := ioutil.TempDir("", "annotate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	data := rotatedJPEG(t, 120, 80)
+	object := annotate.DefaultColors[annotate.KindObject]
+	// The box is in the stored 120x80 image; displayed upright, the image
+	// is 80x120 and the box is at 50,10 to 70,50.
+	boxes := []annotate.Box{{Kind: annotate.KindObject, Rect: image.Rect(10, 10, 50, 30)}}
+
+	tests := []struct {
+		name     string
+		stored   bool
+		drawn    image.Point
+		notDrawn image.Point
+	}{
+		{name: "stored", stored: true, drawn: image.Pt(50, 30), notDrawn: image.Pt(10, 20)},
+		{name: "upright", stored: false, drawn: image.Pt(10, 20), notDrawn: image.Pt(50, 30)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(dir, test.name+".png")
+			if err := annotate.WriteFile(path, bytes.NewReader(data), boxes, annotate.Options{NoLabels: true, Stored: test.stored}); err != nil {
+				t.Fatal(err)
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			img, err := png.Decode(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if img.Bounds() != image.Rect(0, 0, 80, 120) {
+				t.Errorf("bounds = %v, want the image upright", img.Bounds())
+			}
+			if !sameColor(img.At(test.drawn.X, test.drawn.Y), object) {
+				t.Errorf("pixel at %v = %v, want the outline", test.drawn, img.At(test.drawn.X, test.drawn.Y))
+			}
+			if sameColor(img.At(test.notDrawn.X, test.notDrawn.Y), object) {
+				t.Errorf("pixel at %v is outlined, want the box elsewhere", test.notDrawn)
+			}
+		})
+	}
+}
@@ -0,0 +1,223 @@
+package annotate
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/preprocess"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// DefaultColors are the colors of each kind of box.
+var DefaultColors = map[string]color.Color{
+	KindFace:      color.RGBA{R: 0x1f, G: 0x77, B: 0xb4, A: 0xff},
+	KindObject:    color.RGBA{R: 0xff, G: 0x7f, B: 0x0e, A: 0xff},
+	KindBrand:     color.RGBA{R: 0x94, G: 0x67, B: 0xbd, A: 0xff},
+	KindCelebrity: color.RGBA{R: 0xd6, G: 0x27, B: 0x28, A: 0xff},
+	KindText:      color.RGBA{R: 0x2c, G: 0xa0, B: 0x2c, A: 0xff},
+}
+
+// fallbackColor is used for the kinds missing from the colors in use.
+var fallbackColor = color.RGBA{R: 0xff, G: 0xff, B: 0x00, A: 0xff}
+
+// Options change how the boxes are drawn. The zero value uses the defaults
+// listed on each field.
+type Options struct {
+	// LineWidth is the width of the outlines, in pixels. Defaults to one
+	// pixel per 300 pixels of the shorter side of the image, and at least 2.
+	LineWidth int
+	// Colors replace DefaultColors for the kinds they list.
+	Colors map[string]color.Color
+	// NoLabels draws the outlines only.
+	NoLabels bool
+	// Stored tells Render and WriteFile that the boxes were found in the
+	// image as its pixels are stored, not turned by its EXIF orientation:
+	// a remote image, or a local one uploaded without a Preprocessor. The
+	// boxes are then turned with the image.
+	Stored bool
+}
+
+// JPEGQuality is the quality of the annotated JPEG images.
+const JPEGQuality = 90
+
+// labelPadding is the space around the text of a label, in pixels.
+const labelPadding = 2
+
+// Draw outlines the boxes on dst and labels them. Boxes are clipped to the
+// bounds of dst.
+func Draw(dst draw.Image, boxes []Box, options Options) {
+	bounds := dst.Bounds()
+	lineWidth := options.LineWidth
+	if lineWidth <= 0 {
+		lineWidth = bounds.Dx()
+		if bounds.Dy() < lineWidth {
+			lineWidth = bounds.Dy()
+		}
+		lineWidth /= 300
+		if lineWidth < 2 {
+			lineWidth = 2
+		}
+	}
+
+	for _, box := range boxes {
+		rect := box.Rect.Canon().Intersect(bounds)
+		if rect.Empty() {
+			continue
+		}
+		boxColor := image.NewUniform(options.color(box.Kind))
+		drawOutline(dst, rect, lineWidth, boxColor)
+		if !options.NoLabels {
+			drawLabel(dst, rect, label(box), boxColor)
+		}
+	}
+}
+
+// Annotate returns a copy of src with the boxes drawn on it.
+func Annotate(src image.Image, boxes []Box, options Options) *image.RGBA {
+	dst := image.NewRGBA(src.Bounds())
+	draw.Draw(dst, dst.Bounds(), src, src.Bounds().Min, draw.Src)
+	Draw(dst, boxes, options)
+	return dst
+}
+
+// Render decodes a JPEG or PNG image from r, draws the boxes on it, and
+// encodes it to w in the same format. It returns the name of the format.
+func Render(w io.Writer, r io.Reader, boxes []Box, options Options) (string, error) {
+	src, boxes, format, err := decode(r, boxes, options)
+	if err != nil {
+		return "", err
+	}
+	return format, encode(w, Annotate(src, boxes, options), format)
+}
+
+// WriteFile decodes a JPEG or PNG image from r, draws the boxes on it, and
+// writes it to path, as a JPEG if path ends in .jpg or .jpeg and as a PNG
+// otherwise.
+func WriteFile(path string, r io.Reader, boxes []Box, options Options) error {
+	src, boxes, _, err := decode(r, boxes, options)
+	if err != nil {
+		return err
+	}
+
+	format := "png"
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jpg", ".jpeg":
+		format = "jpeg"
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = encode(file, Annotate(src, boxes, options), format)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// decode reads an image and turns it the way it is displayed, as the
+// preprocess package does before upload, so that the boxes of a preprocessed
+// image line up. The copy is written upright, without the EXIF orientation.
+// With options.Stored, the boxes are turned too.
+func decode(r io.Reader, boxes []Box, options Options) (image.Image, []Box, string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("annotate: reading the image: %w", err)
+	}
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("annotate: decoding the image: %w", err)
+	}
+	orientation := preprocess.Orientation(data)
+	if options.Stored && orientation != preprocess.OrientationNormal {
+		bounds := src.Bounds()
+		turned := make([]Box, len(boxes))
+		for i, box := range boxes {
+			turned[i] = box
+			turned[i].Rect = preprocess.OrientRect(box.Rect.Sub(bounds.Min), orientation, bounds.Dx(), bounds.Dy())
+		}
+		boxes = turned
+	}
+	return preprocess.Orient(src, orientation), boxes, format, nil
+}
+
+func encode(w io.Writer, img image.Image, format string) error {
+	if format == "jpeg" {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: JPEGQuality})
+	}
+	return png.Encode(w, img)
+}
+
+func (o Options) color(kind string) color.Color {
+	if c, ok := o.Colors[kind]; ok {
+		return c
+	}
+	if c, ok := DefaultColors[kind]; ok {
+		return c
+	}
+	return fallbackColor
+}
+
+// label returns the text drawn above a box.
+func label(box Box) string {
+	if box.Confidence > 0 {
+		return fmt.Sprintf("%v %.0f%%", box.Label, box.Confidence*100)
+	}
+	return box.Label
+}
+
+// drawOutline draws the sides of rect, inside it, width pixels wide.
+func drawOutline(dst draw.Image, rect image.Rectangle, width int, src image.Image) {
+	if 2*width >= rect.Dx() || 2*width >= rect.Dy() {
+		draw.Draw(dst, rect, src, image.Point{}, draw.Over)
+		return
+	}
+	sides := []image.Rectangle{
+		image.Rect(rect.Min.X, rect.Min.Y, rect.Max.X, rect.Min.Y+width),
+		image.Rect(rect.Min.X, rect.Max.Y-width, rect.Max.X, rect.Max.Y),
+		image.Rect(rect.Min.X, rect.Min.Y+width, rect.Min.X+width, rect.Max.Y-width),
+		image.Rect(rect.Max.X-width, rect.Min.Y+width, rect.Max.X, rect.Max.Y-width),
+	}
+	for _, side := range sides {
+		draw.Draw(dst, side, src, image.Point{}, draw.Over)
+	}
+}
+
+// drawLabel writes text in white on the box color, above the top-left
+// corner of rect, or just inside it when there is no room above.
+func drawLabel(dst draw.Image, rect image.Rectangle, text string, fill image.Image) {
+	if text == "" {
+		return
+	}
+	face := basicfont.Face7x13
+	metrics := face.Metrics()
+	width := font.MeasureString(face, text).Ceil() + 2*labelPadding
+	height := metrics.Height.Ceil() + 2*labelPadding
+
+	top := rect.Min.Y - height
+	if top < dst.Bounds().Min.Y {
+		top = rect.Min.Y
+	}
+	area := image.Rect(rect.Min.X, top, rect.Min.X+width, top+height).Intersect(dst.Bounds())
+	draw.Draw(dst, area, fill, image.Point{}, draw.Over)
+
+	drawer := font.Drawer{
+		Dst:  dst,
+		Src:  image.White,
+		Face: face,
+		Dot:  fixed.P(rect.Min.X+labelPadding, top+labelPadding+metrics.Ascent.Ceil()),
+	}
+	drawer.DrawString(text)
+}
@@ -0,0 +1,116 @@
+// Package auth provides the credentials a visionkit client sends with each
+// request. A Provider returns the header to add, and Authorizer adapts it to
+// the autorest.Authorizer the SDK expects:
+//
+//	provider := auth.Chain(auth.NewKeyFile("/var/run/secrets/computervision/key"), auth.StaticKey(key))
+//	client := visionkit.NewWithAuthorizer(endpointURL, auth.Authorizer(provider))
+//
+// The providers are asked for the credential on every request, so a key that
+// is rotated (in a file, with RotatingKey.Set, or by the token command) is
+// used without restarting the program.
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// SubscriptionKeyHeader is the header that carries a subscription key.
+const SubscriptionKeyHeader = "Ocp-Apim-Subscription-Key"
+
+// ErrNoCredential is returned by a Provider that has nothing to offer, for
+// example a key file that does not exist. Chain moves on to the next one.
+var ErrNoCredential = errors.New("auth: no credential available")
+
+// Provider returns the header that authorizes a request.
+type Provider interface {
+	Credential(ctx context.Context) (header, value string, err error)
+}
+
+// Authorizer adapts a Provider to autorest. A failing Provider fails the
+// request before it is sent.
+func Authorizer(provider Provider) autorest.Authorizer {
+	return authorizer{provider}
+}
+
+type authorizer struct {
+	provider Provider
+}
+
+func (a authorizer) WithAuthorization() autorest.PrepareDecorator {
+	return func(p autorest.Preparer) autorest.Preparer {
+		return autorest.PreparerFunc(func(r *http.Request) (*http.Request, error) {
+			r, err := p.Prepare(r)
+			if err != nil {
+				return r, err
+			}
+			header, value, err := a.provider.Credential(r.Context())
+			if err != nil {
+				return r, err
+			}
+			return autorest.Prepare(r, autorest.WithHeader(header, value))
+		})
+	}
+}
+
+// StaticKey is a subscription key that never changes.
+type StaticKey string
+
+// Credential returns the key, or ErrNoCredential if it is empty.
+func (k StaticKey) Credential(ctx context.Context) (string, string, error) {
+	if k == "" {
+		return "", "", ErrNoCredential
+	}
+	return SubscriptionKeyHeader, string(k), nil
+}
+
+// RotatingKey is a subscription key that can be replaced while requests are
+// running, for example from a signal handler or an admin endpoint.
+type RotatingKey struct {
+	mu  sync.RWMutex
+	key string
+}
+
+// NewRotatingKey returns a RotatingKey holding key.
+func NewRotatingKey(key string) *RotatingKey {
+	return &RotatingKey{key: key}
+}
+
+// Set replaces the key used by the next requests.
+func (k *RotatingKey) Set(key string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.key = strings.TrimSpace(key)
+}
+
+// Credential returns the current key, or ErrNoCredential if it is empty.
+func (k *RotatingKey) Credential(ctx context.Context) (string, string, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return StaticKey(k.key).Credential(ctx)
+}
+
+// Chain returns a Provider that asks each provider in turn and uses the
+// first credential found. A provider that fails with an error other than
+// ErrNoCredential stops the chain.
+func Chain(providers ...Provider) Provider {
+	return chain(providers)
+}
+
+type chain []Provider
+
+func (c chain) Credential(ctx context.Context) (string, string, error) {
+	for _, provider := range c {
+		header, value, err := provider.Credential(ctx)
+		if errors.Is(err, ErrNoCredential) {
+			continue
+		}
+		return header, value, err
+	}
+	return "", "", ErrNoCredential
+}
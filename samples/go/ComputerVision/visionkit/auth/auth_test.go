@@ -0,0 +1,168 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/auth"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+)
+
+// failing is a Provider that fails with err.
+type failing struct{ err error }
+
+func (f failing) Credential(ctx context.Context) (string, string, error) { return "", "", f.err }
+
+func TestChain(t *testing.T) {
+	broken := errors.New("broken")
+	tests := []struct {
+		name      string
+		providers []auth.Provider
+		wantValue string
+		wantErr   error
+	}{
+		{name: "first", providers: []auth.Provider{auth.StaticKey("a"), auth.StaticKey("b")}, wantValue: "a"},
+		{name: "skips empty", providers: []auth.Provider{auth.StaticKey(""), auth.NewRotatingKey(""), auth.StaticKey("b")}, wantValue: "b"},
+		{name: "skips missing file", providers: []auth.Provider{auth.NewKeyFile("/nonexistent/key"), auth.StaticKey("b")}, wantValue: "b"},
+		{name: "stops at failure", providers: []auth.Provider{failing{broken}, auth.StaticKey("b")}, wantErr: broken},
+		{name: "nothing", providers: []auth.Provider{auth.StaticKey("")}, wantErr: auth.ErrNoCredential},
+		{name: "empty", wantErr: auth.ErrNoCredential},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			header, value, err := auth.Chain(test.providers...).Credential(context.Background())
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Errorf("error = %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if header != auth.SubscriptionKeyHeader || value != test.wantValue {
+				t.Errorf("Credential = %v: %v, want %v: %v", header, value, auth.SubscriptionKeyHeader, test.wantValue)
+			}
+		})
+	}
+}
+
+func TestRotatingKey(t *testing.T) {
+	key := auth.NewRotatingKey("old")
+	key.Set("  new \n")
+	if _, value, err := key.Credential(context.Background()); err != nil || value != "new" {
+		t.Errorf("Credential = %q, %v, want the new key trimmed", value, err)
+	}
+	key.Set("")
+	if _, _, err := key.Credential(context.Background()); !errors.Is(err, auth.ErrNoCredential) {
+		t.Errorf("error = %v, want %v after clearing the key", err, auth.ErrNoCredential)
+	}
+}
+
+func TestKeyFileRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "key")
+	keyFile := auth.NewKeyFile(path)
+	keyFile.CheckInterval = -1
+
+	if _, _, err := keyFile.Credential(context.Background()); !errors.Is(err, auth.ErrNoCredential) {
+		t.Errorf("error = %v, want %v before the file exists", err, auth.ErrNoCredential)
+	}
+	for _, key := range []string{"first-key", "second-key-rotated"} {
+		if err := ioutil.WriteFile(path, []byte(key+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, value, err := keyFile.Credential(context.Background()); err != nil || value != key {
+			t.Errorf("Credential = %q, %v, want %q", value, err, key)
+		}
+	}
+
+	cached := auth.NewKeyFile(path)
+	cached.CheckInterval = time.Hour
+	cached.Credential(context.Background())
+	ioutil.WriteFile(path, []byte("third-key-not-seen-yet"), 0600)
+	if _, value, _ := cached.Credential(context.Background()); value != "second-key-rotated" {
+		t.Errorf("Credential = %q, want the key read before the check interval ran out", value)
+	}
+}
+
+func TestTokenCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	count := filepath.Join(dir, "count")
+
+	// The command counts its runs and prints a token that expires within the
+	// default refresh margin.
+	script := `echo x >> "$0"; echo '{"accessToken": "token-1", "expires_in": 30}'`
+	command := auth.NewTokenCommand("sh", "-c", script, count)
+	for i := 0; i < 3; i++ {
+		header, value, err := command.Credential(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header != "Authorization" || value != "Bearer token-1" {
+			t.Errorf("Credential = %v: %v, want a bearer token", header, value)
+		}
+	}
+	if runs, _ := ioutil.ReadFile(count); len(runs) != 2*3 {
+		t.Errorf("command ran %v times, want 3 with a token inside the refresh margin", len(runs)/2)
+	}
+
+	os.Remove(count)
+	cached := auth.NewTokenCommand("sh", "-c", script, count)
+	cached.RefreshMargin = time.Second
+	for i := 0; i < 3; i++ {
+		cached.Credential(context.Background())
+	}
+	if runs, _ := ioutil.ReadFile(count); len(runs) != 2 {
+		t.Errorf("command ran %v times, want 1 with the token cached", len(runs)/2)
+	}
+
+	failing := auth.NewTokenCommand("sh", "-c", "echo expired >&2; exit 1")
+	if _, _, err := failing.Credential(context.Background()); err == nil {
+		t.Error("Credential succeeded with a failing command")
+	}
+}
+
+func TestAuthorizer(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	server.Token = "token-1"
+	image := visionkit.URL("https://example.com/dog.jpg")
+
+	key := auth.NewRotatingKey(cvtest.Key)
+	client := visionkit.NewWithAuthorizer(server.URL, auth.Authorizer(key))
+	client.Retry.MaxAttempts = 1
+	if _, err := client.Tag(context.Background(), image); err != nil {
+		t.Fatalf("Tag with the key: %v", err)
+	}
+
+	key.Set("")
+	_, err := client.Tag(context.Background(), image)
+	if !errors.Is(err, auth.ErrNoCredential) {
+		t.Errorf("error = %v, want %v", err, auth.ErrNoCredential)
+	}
+	if requests := server.RequestCount(cvtest.RouteTag); requests != 1 {
+		t.Errorf("requests = %v, want the one without a credential not sent", requests)
+	}
+
+	token := auth.NewTokenCommand("echo", "token-1")
+	client = visionkit.NewWithAuthorizer(server.URL, auth.Authorizer(auth.Chain(key, token)))
+	client.Retry.MaxAttempts = 1
+	if _, err := client.Tag(context.Background(), image); err != nil {
+		t.Errorf("Tag with the token: %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults of TokenCommand.
+const (
+	// DefaultTokenLifetime is how long a token without an expiry is used.
+	DefaultTokenLifetime = 5 * time.Minute
+	// DefaultRefreshMargin is how long before its expiry a token is replaced.
+	DefaultRefreshMargin = time.Minute
+	// DefaultCommandTimeout limits each run of the command.
+	DefaultCommandTimeout = 30 * time.Second
+)
+
+// TokenCommand gets an Azure AD bearer token by running a local command, for
+// example
+//
+//	az account get-access-token --resource https://cognitiveservices.azure.com
+//
+// Bearer tokens only work with custom subdomain endpoints, such as
+// https://my-resource.cognitiveservices.azure.com, not with regional ones.
+//
+// The command prints either the bare token or a JSON object with the token in
+// accessToken, access_token, or token, and its expiry in expiresOn,
+// expires_on (a time or Unix seconds), or expires_in (seconds from now). The
+// token is cached until RefreshMargin before its expiry.
+type TokenCommand struct {
+	Name string
+	Args []string
+	// Timeout limits each run. Defaults to DefaultCommandTimeout.
+	Timeout time.Duration
+	// RefreshMargin defaults to DefaultRefreshMargin.
+	RefreshMargin time.Duration
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewTokenCommand returns a TokenCommand that runs name with args.
+func NewTokenCommand(name string, args ...string) *TokenCommand {
+	return &TokenCommand{Name: name, Args: args}
+}
+
+// Credential returns an Authorization header with the cached token, running
+// the command first if the token is missing or about to expire.
+func (c *TokenCommand) Credential(ctx context.Context) (string, string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	margin := c.RefreshMargin
+	if margin <= 0 {
+		margin = DefaultRefreshMargin
+	}
+	if c.token == "" || time.Now().After(c.expires.Add(-margin)) {
+		token, expires, err := c.run(ctx)
+		if err != nil {
+			return "", "", err
+		}
+		c.token, c.expires = token, expires
+	}
+	return "Authorization", "Bearer " + c.token, nil
+}
+
+func (c *TokenCommand) run(ctx context.Context) (string, time.Time, error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", time.Time{}, fmt.Errorf("auth: token command %v: %w: %s", c.Name, err, message)
+		}
+		return "", time.Time{}, fmt.Errorf("auth: token command %v: %w", c.Name, err)
+	}
+
+	token, expires, err := parseToken(stdout.Bytes(), time.Now())
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("auth: token command %v: %w", c.Name, err)
+	}
+	return token, expires, nil
+}
+
+// parseToken reads the output of a token command.
+func parseToken(output []byte, now time.Time) (string, time.Time, error) {
+	output = bytes.TrimSpace(output)
+	if len(output) == 0 {
+		return "", time.Time{}, errors.New("no token in the output")
+	}
+	if output[0] != '{' {
+		return string(output), now.Add(DefaultTokenLifetime), nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(output, &fields); err != nil {
+		return "", time.Time{}, err
+	}
+	var token string
+	for _, name := range []string{"accessToken", "access_token", "token"} {
+		if value, ok := fields[name].(string); ok && value != "" {
+			token = value
+			break
+		}
+	}
+	if token == "" {
+		return "", time.Time{}, errors.New("no accessToken, access_token, or token in the output")
+	}
+
+	expires := now.Add(DefaultTokenLifetime)
+	if value, ok := fields["expires_in"]; ok {
+		if seconds, ok := number(value); ok {
+			expires = now.Add(time.Duration(seconds) * time.Second)
+		}
+	} else {
+		for _, name := range []string{"expiresOn", "expires_on"} {
+			if t, ok := expiryTime(fields[name]); ok {
+				expires = t
+				break
+			}
+		}
+	}
+	return token, expires, nil
+}
+
+// expiryTime reads an absolute expiry: Unix seconds, RFC 3339, or the local
+// time format printed by the Azure CLI.
+func expiryTime(value interface{}) (time.Time, bool) {
+	if seconds, ok := number(value); ok {
+		return time.Unix(seconds, 0), true
+	}
+	text, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	if t, err := time.Parse(time.RFC3339, text); err == nil {
+		return t, true
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05.999999", text, time.Local); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// number reads a JSON number, or a string holding an integer.
+func number(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return int64(v), true
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
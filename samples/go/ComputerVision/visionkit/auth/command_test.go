@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseToken(t *testing.T) {
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		output      string
+		wantToken   string
+		wantExpires time.Time
+		wantErr     bool
+	}{
+		{name: "bare token", output: "  eyJ0eXAi\n", wantToken: "eyJ0eXAi", wantExpires: now.Add(DefaultTokenLifetime)},
+		{
+			name:        "azure cli",
+			output:      `{"accessToken": "a", "expiresOn": "2020-05-01T13:00:00Z", "tokenType": "Bearer"}`,
+			wantToken:   "a",
+			wantExpires: time.Date(2020, 5, 1, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "unix seconds",
+			output:      `{"access_token": "b", "expires_on": "1588338000"}`,
+			wantToken:   "b",
+			wantExpires: time.Unix(1588338000, 0),
+		},
+		{name: "expires in", output: `{"token": "c", "expires_in": 600}`, wantToken: "c", wantExpires: now.Add(10 * time.Minute)},
+		{name: "expires in wins", output: `{"token": "d", "expires_in": 60, "expiresOn": "2030-01-01T00:00:00Z"}`, wantToken: "d", wantExpires: now.Add(time.Minute)},
+		{name: "no expiry", output: `{"accessToken": "e"}`, wantToken: "e", wantExpires: now.Add(DefaultTokenLifetime)},
+		{name: "empty", output: " \n", wantErr: true},
+		{name: "no token", output: `{"expires_in": 600}`, wantErr: true},
+		{name: "bad json", output: `{"accessToken": `, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			token, expires, err := parseToken([]byte(test.output), now)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("parseToken succeeded with %q", token)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token != test.wantToken || !expires.Equal(test.wantExpires) {
+				t.Errorf("parseToken = %q, %v, want %q, %v", token, expires, test.wantToken, test.wantExpires)
+			}
+		})
+	}
+}
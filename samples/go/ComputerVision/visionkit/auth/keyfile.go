@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCheckInterval is how often a KeyFile looks for a new key by
+// default.
+const DefaultCheckInterval = 5 * time.Second
+
+// KeyFile reads the subscription key from a file, such as a Kubernetes
+// secret mount or a Docker secret. The file is read again when its size or
+// modification time changes, checked at most once per CheckInterval, so a
+// rotated key is picked up without a restart.
+type KeyFile struct {
+	Path string
+	// CheckInterval defaults to DefaultCheckInterval; a negative value
+	// checks the file on every request.
+	CheckInterval time.Duration
+
+	mu        sync.Mutex
+	key       string
+	modTime   time.Time
+	size      int64
+	checkedAt time.Time
+}
+
+// NewKeyFile returns a KeyFile for path.
+func NewKeyFile(path string) *KeyFile {
+	return &KeyFile{Path: path}
+}
+
+// Credential returns the key in the file. It returns ErrNoCredential if the
+// file does not exist or is empty.
+func (f *KeyFile) Credential(ctx context.Context) (string, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	interval := f.CheckInterval
+	if interval == 0 {
+		interval = DefaultCheckInterval
+	}
+	now := time.Now()
+	if f.checkedAt.IsZero() || now.Sub(f.checkedAt) >= interval {
+		if err := f.reload(); err != nil {
+			return "", "", err
+		}
+		f.checkedAt = now
+	}
+	return StaticKey(f.key).Credential(ctx)
+}
+
+// reload reads the file if it changed since the last read.
+func (f *KeyFile) reload() error {
+	info, err := os.Stat(f.Path)
+	if os.IsNotExist(err) {
+		f.key = ""
+		return fmt.Errorf("%w: key file %v does not exist", ErrNoCredential, f.Path)
+	}
+	if err != nil {
+		return fmt.Errorf("auth: %w", err)
+	}
+	if info.ModTime().Equal(f.modTime) && info.Size() == f.size && f.key != "" {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(f.Path)
+	if err != nil {
+		return fmt.Errorf("auth: %w", err)
+	}
+	f.key = strings.TrimSpace(string(data))
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+	return nil
+}
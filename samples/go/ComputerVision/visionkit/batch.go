@@ -0,0 +1,200 @@
+package visionkit
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+)
+
+// DefaultConcurrency is the number of images processed at once by RunBatch
+// when no concurrency is given.
+const DefaultConcurrency = 4
+
+// imageExtensions are the file extensions picked up by FindImages.
+var imageExtensions = map[string]bool{
+	".jpg":  true,
+	".jpeg": true,
+	".png":  true,
+	".gif":  true,
+	".bmp":  true,
+}
+
+// convertedExtensions are the file extensions of the formats the service
+// does not take but the preprocess package converts before upload. They are
+// picked up by Client.FindImages when the client has a Preprocessor.
+var convertedExtensions = map[string]bool{
+	".tif":  true,
+	".tiff": true,
+	".webp": true,
+}
+
+// FindImages walks the directory tree at root and returns a source for every
+// JPEG, PNG, GIF, or BMP file in it, in lexical order. See Client.FindImages
+// for the formats a Preprocessor converts.
+func FindImages(root string) ([]ImageSource, error) {
+	return findImages(root, false)
+}
+
+// FindImages is like the FindImages function, and also returns the TIFF and
+// WebP files when the client has a Preprocessor to convert them.
+func (c *Client) FindImages(root string) ([]ImageSource, error) {
+	return findImages(root, c.Preprocessor != nil)
+}
+
+func findImages(root string, converted bool) ([]ImageSource, error) {
+	var images []ImageSource
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		extension := strings.ToLower(filepath.Ext(path))
+		if !info.IsDir() && (imageExtensions[extension] || converted && convertedExtensions[extension]) {
+			images = append(images, File(path))
+		}
+		return nil
+	})
+	return images, err
+}
+
+// GlobImages returns a source for every file matching pattern, using the
+// syntax of filepath.Match.
+func GlobImages(pattern string) ([]ImageSource, error) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	images := make([]ImageSource, 0, len(matches))
+	for _, match := range matches {
+		images = append(images, File(match))
+	}
+	return images, nil
+}
+
+// ReadImageList reads a newline-delimited list of image URLs or paths, like
+// the ImageFiles.txt used by the Content Moderator sample. Blank lines and
+// lines starting with "#" are skipped.
+func ReadImageList(r io.Reader) ([]ImageSource, error) {
+	var images []ImageSource
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		images = append(images, ParseSource(line))
+	}
+	return images, scanner.Err()
+}
+
+// ExpandSources turns batch arguments into sources: directories are walked
+// with FindImages, arguments containing glob characters are expanded with
+// GlobImages, and everything else goes through ParseSource.
+func ExpandSources(args []string) ([]ImageSource, error) {
+	return expandSources(args, FindImages)
+}
+
+// ExpandSources is like the ExpandSources function, and walks directories
+// with Client.FindImages.
+func (c *Client) ExpandSources(args []string) ([]ImageSource, error) {
+	return expandSources(args, c.FindImages)
+}
+
+func expandSources(args []string, find func(root string) ([]ImageSource, error)) ([]ImageSource, error) {
+	var images []ImageSource
+	for _, arg := range args {
+		source := ParseSource(arg)
+		if _, remote := source.RemoteURL(); remote || arg == "-" {
+			images = append(images, source)
+			continue
+		}
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			found, err := find(arg)
+			if err != nil {
+				return nil, err
+			}
+			images = append(images, found...)
+			continue
+		}
+		if strings.ContainsAny(arg, "*?[") {
+			found, err := GlobImages(arg)
+			if err != nil {
+				return nil, err
+			}
+			images = append(images, found...)
+			continue
+		}
+		images = append(images, source)
+	}
+	return images, nil
+}
+
+// BatchTask is the work done for each image of a batch.
+type BatchTask func(ctx context.Context, image ImageSource) (interface{}, error)
+
+// BatchResult is the outcome of a BatchTask for one image. Index is the
+// position of the image in the batch, since results arrive in completion
+// order.
+type BatchResult struct {
+	Index  int
+	Image  ImageSource
+	Result interface{}
+	Err    error
+}
+
+// RunBatch runs task on every image with at most concurrency images in
+// flight, and sends each result on the returned channel as soon as it
+// completes. The channel is closed once every image has been processed or
+// ctx is cancelled; images that were not started because of the
+// cancellation are reported with the context error.
+func RunBatch(ctx context.Context, images []ImageSource, concurrency int, task BatchTask) <-chan BatchResult {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	indexes := make(chan int)
+	results := make(chan BatchResult)
+
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for index := range indexes {
+				image := images[index]
+				if err := ctx.Err(); err != nil {
+					results <- BatchResult{Index: index, Image: image, Err: err}
+					continue
+				}
+				result, err := task(ctx, image)
+				results <- BatchResult{Index: index, Image: image, Result: result, Err: err}
+			}
+		}()
+	}
+
+	go func() {
+		for index := range images {
+			indexes <- index
+		}
+		close(indexes)
+		workers.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// AnalyzeBatch runs Analyze with the given features on every image. The
+// Result of each BatchResult is an Analysis.
+func (c *Client) AnalyzeBatch(ctx context.Context, images []ImageSource, concurrency int, features []computervision.VisualFeatureTypes) <-chan BatchResult {
+	return RunBatch(ctx, images, concurrency, func(ctx context.Context, image ImageSource) (interface{}, error) {
+		return c.Analyze(ctx, image, features)
+	})
+}
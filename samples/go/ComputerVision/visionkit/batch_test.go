@@ -0,0 +1,210 @@
+package visionkit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/preprocess"
+)
+
+// names returns the names of images.
+func names(images []visionkit.ImageSource) []string {
+	var names []string
+	for _, image := range images {
+		names = append(names, image.Name())
+	}
+	return names
+}
+
+func TestFindImages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "scans"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"b.JPG", "a.png", "notes.txt", "scans/page.tif", "scans/page.tiff", "scans/photo.webp"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	join := func(names ...string) []string {
+		for i, name := range names {
+			names[i] = filepath.Join(dir, name)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name         string
+		preprocessor visionkit.Preprocessor
+		want         []string
+	}{
+		{name: "sent as they are", want: join("a.png", "b.JPG")},
+		{name: "converted", preprocessor: preprocess.Preprocessor{}, want: join("a.png", "b.JPG", "scans/page.tif", "scans/page.tiff", "scans/photo.webp")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := &visionkit.Client{Preprocessor: test.preprocessor}
+			images, err := client.ExpandSources([]string{dir})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := names(images); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("images = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	images, err := visionkit.FindImages(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(images), join("a.png", "b.JPG"); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindImages = %v, want %v", got, want)
+	}
+}
+
+func TestExpandSources(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{"b.jpg", "a.jpg", "c.png"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	args := []string{filepath.Join(dir, "*.jpg"), "https://example.com/dog.jpg", "-", filepath.Join(dir, "c.png")}
+	images, err := visionkit.ExpandSources(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "a.jpg"), filepath.Join(dir, "b.jpg"), "https://example.com/dog.jpg", "stdin", filepath.Join(dir, "c.png")}
+	if got := names(images); !reflect.DeepEqual(got, want) {
+		t.Errorf("images = %v, want %v", got, want)
+	}
+}
+
+func TestReadImageList(t *testing.T) {
+	list := "# images to analyze\nhttps://example.com/dog.jpg\n\n  photos/cat.png  \n"
+	images, err := visionkit.ReadImageList(strings.NewReader(list))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(images), []string{"https://example.com/dog.jpg", "photos/cat.png"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("images = %v, want %v", got, want)
+	}
+	if _, remote := images[0].RemoteURL(); !remote {
+		t.Errorf("%v is not remote", images[0].Name())
+	}
+}
+
+// urls returns n remote images.
+func urls(n int) []visionkit.ImageSource {
+	images := make([]visionkit.ImageSource, n)
+	for i := range images {
+		images[i] = visionkit.URL(fmt.Sprintf("https://example.com/%v.jpg", i))
+	}
+	return images
+}
+
+func TestRunBatch(t *testing.T) {
+	const concurrency = 3
+	images := urls(10)
+	var mu sync.Mutex
+	var running, most int
+	task := func(ctx context.Context, image visionkit.ImageSource) (interface{}, error) {
+		mu.Lock()
+		running++
+		if running > most {
+			most = running
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+		return image.Name(), nil
+	}
+
+	seen := make(map[int]bool)
+	for result := range visionkit.RunBatch(context.Background(), images, concurrency, task) {
+		if seen[result.Index] {
+			t.Errorf("image %v reported twice", result.Index)
+		}
+		seen[result.Index] = true
+		if result.Image != images[result.Index] || result.Result != images[result.Index].Name() || result.Err != nil {
+			t.Errorf("result %v = %+v, want the one of %v", result.Index, result, images[result.Index].Name())
+		}
+	}
+	if len(seen) != len(images) {
+		t.Errorf("results = %v, want %v", len(seen), len(images))
+	}
+	if most > concurrency {
+		t.Errorf("%v images in flight, want at most %v", most, concurrency)
+	}
+}
+
+func TestAnalyzeBatchFailures(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	// One image at a time, so the second image gets the error.
+	server.Enqueue(cvtest.RouteAnalyze, cvtest.Response{Body: cvtest.AnalyzeBody}, cvtest.ServerError(400))
+	images := urls(4)
+
+	failed := 0
+	for result := range client.AnalyzeBatch(context.Background(), images, 1, nil) {
+		if result.Err != nil {
+			failed++
+			if result.Index != 1 || statusCode(result.Err) != 400 {
+				t.Errorf("image %v failed with %v, want only image 1 with a 400", result.Index, result.Err)
+			}
+			continue
+		}
+		if analysis, ok := result.Result.(visionkit.Analysis); !ok || len(analysis.Tags) == 0 {
+			t.Errorf("image %v result = %+v, want an analysis", result.Index, result.Result)
+		}
+	}
+	if failed != 1 {
+		t.Errorf("failed = %v, want 1", failed)
+	}
+	if requests := server.RequestCount(cvtest.RouteAnalyze); requests != len(images) {
+		t.Errorf("requests = %v, want one per image", requests)
+	}
+}
+
+func TestRunBatchCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	images := urls(5)
+	results := 0
+	for result := range visionkit.RunBatch(ctx, images, 2, func(ctx context.Context, image visionkit.ImageSource) (interface{}, error) {
+		t.Errorf("task ran for %v after the cancellation", image.Name())
+		return nil, nil
+	}) {
+		results++
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("image %v error = %v, want %v", result.Index, result.Err, context.Canceled)
+		}
+	}
+	if results != len(images) {
+		t.Errorf("results = %v, want every image reported", results)
+	}
+}
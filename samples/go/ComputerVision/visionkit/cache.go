@@ -0,0 +1,173 @@
+package visionkit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// APIVersion is the version of the Computer Vision API called by the client.
+// It is part of every cache key, so results of another version are never
+// returned from a cache.
+const APIVersion = "2.0"
+
+// resultVersion is the version of the JSON form of the result types, which
+// is also part of every cache key, so that entries written by an older
+// visionkit are not decoded into the new types.
+const resultVersion = "3"
+
+// Cache stores the results of the client's tasks. Keys are hex SHA-256
+// digests and values are the JSON encoding of a result. The cache package has
+// an in-memory LRU and an on-disk implementation.
+type Cache interface {
+	// Get returns the value stored under key, and false if there is none
+	// or it has expired.
+	Get(key string) ([]byte, bool, error)
+	// Set stores value under key.
+	Set(key string, value []byte) error
+}
+
+// cached returns the result of a task from the client's Cache, if any, or
+// runs fetch and stores its result. v points to the result, which fetch
+// fills in. params are the arguments of the task that change its result,
+// such as the visual features or the text recognition mode.
+//
+// A local image is read into memory to hash it, and fetch receives the
+// buffered copy so the image is only read once. The cache is looked up
+// before the image goes through the client's Preprocessor, so a cached result
+// is returned without decoding the image, and even for an image the
+// Preprocessor would reject. On a miss, fetch receives the prepared image and
+// the locations in its result are scaled back to the original image; the
+// cache key is made from the original and the client's preprocessing, which
+// decides whether the locations are in the upright or the stored image. A cache that fails to read or write
+// is treated as a miss and does not fail the task.
+func (c *Client) cached(ctx context.Context, op string, image ImageSource, params []string, v interface{}, fetch func(image ImageSource) error) error {
+	if c.Cache == nil {
+		return c.fetchPrepared(op, image, v, fetch)
+	}
+
+	identity, image, err := imageIdentity(image)
+	if err != nil {
+		return err
+	}
+	key := c.cacheKey(op, identity, params)
+	if data, ok, err := c.Cache.Get(key); ok && err == nil {
+		if json.Unmarshal(data, v) == nil {
+			return nil
+		}
+	}
+
+	if err := c.fetchPrepared(op, image, v, fetch); err != nil {
+		return err
+	}
+	if data, err := json.Marshal(v); err == nil {
+		c.Cache.Set(key, data)
+	}
+	return nil
+}
+
+// fetchPrepared prepares the image for the task op, runs fetch, and maps its
+// result back to the original image.
+func (c *Client) fetchPrepared(op string, image ImageSource, v interface{}, fetch func(image ImageSource) error) error {
+	image, err := c.prepare(op, image)
+	if err != nil {
+		return err
+	}
+	if err := fetch(image); err != nil {
+		return err
+	}
+	if prepared, ok := image.(preparedImage); ok {
+		scaleBack(v, prepared.scale)
+	}
+	return nil
+}
+
+// cacheKey hashes everything that changes the result of a task: the API and
+// result versions, the task, the image, its parameters, and the client's
+// Details, Language, and preprocessing.
+func (c *Client) cacheKey(op, identity string, params []string) string {
+	details := make([]string, len(c.Details))
+	for i, detail := range c.Details {
+		details[i] = strings.ToLower(string(detail))
+	}
+	hash := sha256.New()
+	for _, part := range []string{
+		APIVersion,
+		resultVersion,
+		op,
+		identity,
+		strings.Join(params, ","),
+		strings.Join(details, ","),
+		c.Language,
+		c.preprocessing(),
+	} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// preprocessing describes the client's Preprocessor, by its type and, if it
+// is a fmt.Stringer, its settings, or says there is none, in which case
+// images are uploaded as they are stored.
+func (c *Client) preprocessing() string {
+	switch p := c.Preprocessor.(type) {
+	case nil:
+		return "none"
+	case fmt.Stringer:
+		return fmt.Sprintf("%T %v", p, p)
+	}
+	return fmt.Sprintf("%T", c.Preprocessor)
+}
+
+// imageIdentity returns the normalized URL of a remote image, or the SHA-256
+// digest of the bytes of a local one together with a copy of the image held
+// in memory. A prepared or pending image is identified by the original
+// bytes.
+func imageIdentity(image ImageSource) (string, ImageSource, error) {
+	if imageURL, ok := image.RemoteURL(); ok {
+		return "url:" + normalizeURL(imageURL), image, nil
+	}
+	switch i := image.(type) {
+	case preparedImage:
+		return i.identity, image, nil
+	case *pendingImage:
+		return i.identity, image, nil
+	}
+
+	buffered, ok := image.(BytesSource)
+	if !ok {
+		var err error
+		if buffered, err = readAll(image); err != nil {
+			return "", nil, err
+		}
+		image = buffered
+	}
+	return digest(buffered.Data), image, nil
+}
+
+// digest returns the identity of the bytes of a local image.
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// normalizeURL lowercases the scheme and host of an image URL and drops the
+// default port and the fragment, none of which change the image fetched.
+func normalizeURL(imageURL string) string {
+	u, err := url.Parse(imageURL)
+	if err != nil {
+		return imageURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if (u.Scheme == "https" && strings.HasSuffix(u.Host, ":443")) || (u.Scheme == "http" && strings.HasSuffix(u.Host, ":80")) {
+		u.Host = u.Host[:strings.LastIndex(u.Host, ":")]
+	}
+	u.Fragment = ""
+	return u.String()
+}
@@ -0,0 +1,146 @@
+// Package cache stores the results of a visionkit.Client so that an image
+// analyzed once is not sent to the service again. Set a Memory or a Dir as
+// the client's Cache:
+//
+//	client.Cache = cache.NewDir(cache.DefaultDir(), 24*time.Hour)
+//
+// The client keys each result by the SHA-256 digest of the image bytes, or
+// its normalized URL, together with the task, its parameters, the client's
+// Details and Language, and the API version. An entry returned from a cache
+// decodes into the same typed result as a live call.
+package cache
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Defaults of the caches built by the config package.
+const (
+	DefaultTTL           = 24 * time.Hour
+	DefaultMemoryEntries = 1024
+)
+
+// Store is the interface of the caches in this package. It is the same as
+// visionkit.Cache.
+type Store interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte) error
+}
+
+// DefaultDir returns the computervision directory in the user cache
+// directory, for example ~/.cache/computervision on Linux, or
+// computervision-cache in the temporary directory if there is none.
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "computervision-cache")
+	}
+	return filepath.Join(dir, "computervision")
+}
+
+// Memory is an in-memory cache that evicts the least recently used entry
+// once it holds MaxEntries. It is safe for concurrent use.
+type Memory struct {
+	maxEntries int
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	order   *list.List // of *memoryEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemory returns a cache of at most maxEntries entries, each kept for ttl.
+// A maxEntries of 0 or less uses DefaultMemoryEntries, and a ttl of 0 or less
+// keeps entries until they are evicted.
+func NewMemory(maxEntries int, ttl time.Duration) *Memory {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMemoryEntries
+	}
+	return &Memory{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the value stored under key unless it has expired.
+func (m *Memory) Get(key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if expired(entry.expires) {
+		m.order.Remove(element)
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+	m.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set stores value under key, evicting the least recently used entry if the
+// cache is full.
+func (m *Memory) Set(key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry := &memoryEntry{key: key, value: value, expires: expiry(m.ttl)}
+	if element, ok := m.entries[key]; ok {
+		element.Value = entry
+		m.order.MoveToFront(element)
+		return nil
+	}
+	m.entries[key] = m.order.PushFront(entry)
+	for m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of entries in the cache, expired ones included.
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// Refresh returns a cache that never finds an entry but still stores the
+// results it is given, so that every task calls the service again and
+// updates store.
+func Refresh(store Store) Store {
+	return refresh{store}
+}
+
+type refresh struct {
+	Store
+}
+
+func (refresh) Get(key string) ([]byte, bool, error) { return nil, false, nil }
+
+func expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+func expired(expires time.Time) bool {
+	return !expires.IsZero() && time.Now().After(expires)
+}
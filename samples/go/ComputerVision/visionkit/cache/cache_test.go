@@ -0,0 +1,106 @@
+package cache_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cache"
+)
+
+func TestMemoryEviction(t *testing.T) {
+	memory := cache.NewMemory(2, 0)
+	memory.Set("a", []byte("1"))
+	memory.Set("b", []byte("2"))
+	memory.Get("a")
+	memory.Set("c", []byte("3"))
+
+	tests := []struct {
+		key    string
+		wantOK bool
+	}{
+		{"a", true},
+		{"b", false},
+		{"c", true},
+	}
+	for _, test := range tests {
+		if _, ok, _ := memory.Get(test.key); ok != test.wantOK {
+			t.Errorf("Get(%q) found = %v, want %v with the least recently used evicted", test.key, ok, test.wantOK)
+		}
+	}
+	if memory.Len() != 2 {
+		t.Errorf("Len = %v, want 2", memory.Len())
+	}
+}
+
+func TestMemoryTTL(t *testing.T) {
+	memory := cache.NewMemory(0, 10*time.Millisecond)
+	memory.Set("a", []byte("1"))
+	if value, ok, _ := memory.Get("a"); !ok || string(value) != "1" {
+		t.Errorf("Get = %q, %v, want the fresh entry", value, ok)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, ok, _ := memory.Get("a"); ok {
+		t.Error("Get found an expired entry")
+	}
+	if memory.Len() != 0 {
+		t.Errorf("Len = %v, want the expired entry removed", memory.Len())
+	}
+}
+
+func TestDir(t *testing.T) {
+	path, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	dir := cache.NewDir(filepath.Join(path, "entries"), 0)
+	if _, ok, err := dir.Get("abcdef"); ok || err != nil {
+		t.Errorf("Get = %v, %v before Set, want a miss", ok, err)
+	}
+	if err := dir.Set("abcdef", []byte("{\"tags\": []}\n")); err != nil {
+		t.Fatal(err)
+	}
+	if value, ok, err := dir.Get("abcdef"); !ok || err != nil || string(value) != "{\"tags\": []}\n" {
+		t.Errorf("Get = %q, %v, %v, want the value as stored", value, ok, err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "entries", "ab", "abcdef")); err != nil {
+		t.Errorf("entry not in its subdirectory: %v", err)
+	}
+
+	// A second Dir on the same path reads the entries of the first, as a
+	// later run would.
+	expiring := cache.NewDir(filepath.Join(path, "entries"), time.Millisecond)
+	expiring.Set("012345", []byte("x"))
+	if _, ok, _ := dir.Get("012345"); !ok {
+		t.Error("Get missed an entry written by another Dir")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, ok, _ := dir.Get("012345"); ok {
+		t.Error("Get found an expired entry")
+	}
+	if _, err := os.Stat(filepath.Join(path, "entries", "01", "012345")); !os.IsNotExist(err) {
+		t.Errorf("expired entry not removed: %v", err)
+	}
+
+	ioutil.WriteFile(filepath.Join(path, "entries", "ab", "abc999"), []byte("no header"), 0600)
+	if _, ok, _ := dir.Get("abc999"); ok {
+		t.Error("Get found an entry without a header")
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	memory := cache.NewMemory(0, 0)
+	refresh := cache.Refresh(memory)
+	memory.Set("a", []byte("old"))
+	if _, ok, _ := refresh.Get("a"); ok {
+		t.Error("Get found an entry through Refresh")
+	}
+	refresh.Set("a", []byte("new"))
+	if value, _, _ := memory.Get("a"); string(value) != "new" {
+		t.Errorf("Get = %q, want the value stored through Refresh", value)
+	}
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Dir is a cache of plain files in a directory, which lasts across runs and
+// can be shared by processes. Each entry is a file named after its key, in a
+// subdirectory named after the first two characters of the key. The first
+// line of the file holds the expiry time, or 0 for none, and the rest the
+// value.
+type Dir struct {
+	Path string
+	TTL  time.Duration
+}
+
+// NewDir returns a cache in the directory at path, created on the first Set,
+// whose entries are kept for ttl. A ttl of 0 or less keeps them until the
+// files are removed.
+func NewDir(path string, ttl time.Duration) *Dir {
+	return &Dir{Path: path, TTL: ttl}
+}
+
+// Get returns the value stored under key. An expired entry is removed.
+func (d *Dir) Get(key string) ([]byte, bool, error) {
+	path := d.path(key)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	newline := bytes.IndexByte(data, '\n')
+	if newline < 0 {
+		return nil, false, nil
+	}
+	if header := string(data[:newline]); header != "0" {
+		expires, err := time.Parse(time.RFC3339Nano, header)
+		if err != nil || expired(expires) {
+			os.Remove(path)
+			return nil, false, nil
+		}
+	}
+	return data[newline+1:], true, nil
+}
+
+// Set stores value under key. The file is written under a temporary name and
+// then renamed, so a concurrent Get never sees half an entry.
+func (d *Dir) Set(key string, value []byte) error {
+	path := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	header := "0"
+	if expires := expiry(d.TTL); !expires.IsZero() {
+		header = expires.UTC().Format(time.RFC3339Nano)
+	}
+	file, err := ioutil.TempFile(filepath.Dir(path), ".entry-")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append([]byte(header+"\n"), value...))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
+
+func (d *Dir) path(key string) string {
+	prefix := key
+	if len(prefix) > 2 {
+		prefix = prefix[:2]
+	}
+	return filepath.Join(d.Path, prefix, key)
+}
@@ -0,0 +1,260 @@
+package visionkit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	goimage "image"
+	"image/jpeg"
+	"image/png"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cache"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/preprocess"
+)
+
+// halving is a Preprocessor that claims to halve the sides of every image
+// and counts its calls. After the first call it fails, to show that a cached
+// result does not need it.
+type halving struct{ calls int32 }
+
+func (h *halving) Preprocess(data []byte) ([]byte, float64, error) {
+	if atomic.AddInt32(&h.calls, 1) > 1 {
+		return nil, 0, errors.New("preprocessed twice")
+	}
+	return data, 0.5, nil
+}
+
+func TestCacheHitAndMiss(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	client.Cache = cache.NewMemory(0, 0)
+	data := []byte("not really a jpeg")
+
+	tests := []struct {
+		name         string
+		image        visionkit.ImageSource
+		language     string
+		wantRequests int
+	}{
+		{name: "first call", image: image, wantRequests: 1},
+		{name: "same url", image: image, wantRequests: 1},
+		{name: "same url in another case", image: visionkit.URL("HTTPS://EXAMPLE.COM/dog.jpg"), wantRequests: 1},
+		{name: "other url", image: visionkit.URL("https://example.com/cat.jpg"), wantRequests: 2},
+		{name: "other language", image: image, language: "es", wantRequests: 3},
+		{name: "local image", image: visionkit.Bytes("dog.jpg", data), wantRequests: 4},
+		{name: "same bytes under another name", image: visionkit.Bytes("copy.jpg", data), wantRequests: 4},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client.Language = test.language
+			tags, err := client.Tag(context.Background(), test.image)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tags) == 0 {
+				t.Error("Tag returned no tags")
+			}
+			if requests := server.RequestCount(cvtest.RouteTag); requests != test.wantRequests {
+				t.Errorf("requests = %v, want %v", requests, test.wantRequests)
+			}
+		})
+	}
+}
+
+func TestCacheBeforePreprocessor(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	client.Cache = cache.NewMemory(0, 0)
+	preprocessor := &halving{}
+	client.Preprocessor = preprocessor
+	image := visionkit.Bytes("dog.jpg", []byte("not really a jpeg"))
+
+	// The first canned object is at 90,120 230x480 in the uploaded image,
+	// which is half the size of the original.
+	want := geometry.Rect{X: 180, Y: 240, W: 460, H: 960}
+	for i := 0; i < 2; i++ {
+		objects, err := client.DetectObjects(context.Background(), image)
+		if err != nil {
+			t.Fatalf("call %v: %v", i+1, err)
+		}
+		if len(objects) == 0 || objects[0].Rectangle != want {
+			t.Errorf("call %v: objects = %+v, want the first at %+v in the original image", i+1, objects, want)
+		}
+	}
+	if requests := server.RequestCount(cvtest.RouteDetect); requests != 1 {
+		t.Errorf("requests = %v, want 1", requests)
+	}
+	if calls := atomic.LoadInt32(&preprocessor.calls); calls != 1 {
+		t.Errorf("Preprocess ran %v times, want 1", calls)
+	}
+}
+
+func TestCachedPlan(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	client.Cache = cache.NewMemory(0, 0)
+	preprocessor := &halving{}
+	client.Preprocessor = preprocessor
+	image := visionkit.Bytes("dog.jpg", []byte("not really a jpeg"))
+	plan, _ := visionkit.NewPlan("tags", "faces", "objects", "read")
+
+	first, err := client.RunPlan(context.Background(), image, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := len(server.Requests())
+	second, err := client.RunPlan(context.Background(), image, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(server.Requests()); got != requests {
+		t.Errorf("requests = %v after the second run, want %v with every result cached", got, requests)
+	}
+	if calls := atomic.LoadInt32(&preprocessor.calls); calls != 1 {
+		t.Errorf("Preprocess ran %v times, want 1", calls)
+	}
+	if len(first.Analysis.Faces) == 0 || !reflect.DeepEqual(second.Analysis.Faces, first.Analysis.Faces) {
+		t.Errorf("faces = %+v from the cache, want %+v", second.Analysis.Faces, first.Analysis.Faces)
+	}
+}
+
+func TestCacheRefresh(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	store := cache.NewMemory(0, 0)
+	client.Cache = cache.Refresh(store)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Tag(context.Background(), image); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requests := server.RequestCount(cvtest.RouteTag); requests != 2 {
+		t.Errorf("requests = %v, want every call sent", requests)
+	}
+	if store.Len() != 1 {
+		t.Errorf("entries = %v, want the result stored", store.Len())
+	}
+
+	client.Cache = store
+	client.Tag(context.Background(), image)
+	if requests := server.RequestCount(cvtest.RouteTag); requests != 2 {
+		t.Errorf("requests = %v, want the refreshed entry used", requests)
+	}
+}
+
+func TestCachePreprocessing(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	client.Cache = cache.NewMemory(0, 0)
+	var data bytes.Buffer
+	if err := png.Encode(&data, goimage.NewGray(goimage.Rect(0, 0, 64, 64))); err != nil {
+		t.Fatal(err)
+	}
+	image := visionkit.Bytes("scan.png", data.Bytes())
+
+	tests := []struct {
+		name         string
+		preprocessor visionkit.Preprocessor
+		wantRequests int
+	}{
+		{name: "no preprocessor", wantRequests: 1},
+		{name: "default settings", preprocessor: preprocess.Preprocessor{}, wantRequests: 2},
+		{name: "default settings given", preprocessor: preprocess.Preprocessor{MaxDimension: preprocess.MaxDimension}, wantRequests: 2},
+		{name: "other settings", preprocessor: preprocess.Preprocessor{MaxDimension: 1000}, wantRequests: 3},
+		{name: "no preprocessor again", wantRequests: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client.Preprocessor = test.preprocessor
+			if _, err := client.Tag(context.Background(), image); err != nil {
+				t.Fatal(err)
+			}
+			if requests := server.RequestCount(cvtest.RouteTag); requests != test.wantRequests {
+				t.Errorf("requests = %v, want %v", requests, test.wantRequests)
+			}
+		})
+	}
+}
+
+// oriented returns a JPEG image of the given stored size whose EXIF
+// orientation says to turn it by 90 degrees clockwise for display.
+func oriented(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var stored bytes.Buffer
+	if err := jpeg.Encode(&stored, goimage.NewGray(goimage.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatal(err)
+	}
+	exif := []byte("Exif\x00\x00II*\x00\x08\x00\x00\x00")
+	ifd := make([]byte, 2+12+4)
+	binary.LittleEndian.PutUint16(ifd, 1)
+	binary.LittleEndian.PutUint16(ifd[2:], 0x0112)
+	binary.LittleEndian.PutUint16(ifd[4:], 3)
+	binary.LittleEndian.PutUint32(ifd[6:], 1)
+	binary.LittleEndian.PutUint16(ifd[10:], preprocess.OrientationRotate90)
+	exif = append(exif, ifd...)
+
+	data := []byte{0xff, 0xd8, 0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(data[4:], uint16(2+len(exif)))
+	data = append(data, exif...)
+	return append(data, stored.Bytes()[2:]...)
+}
+
+func TestCacheOrientedImage(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	shared := cache.NewMemory(0, 0)
+	upright := server.Client()
+	upright.Cache = shared
+	upright.Preprocessor = preprocess.Preprocessor{}
+	// stored is the client of --no-preprocess.
+	stored := server.Client()
+	stored.Cache = shared
+	data := oriented(t, 120, 80)
+	image := visionkit.Bytes("photo.jpg", data)
+
+	// The results of each client are located in the image it uploaded, so
+	// neither may be returned to the other.
+	tests := []struct {
+		name       string
+		client     *visionkit.Client
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "upright", client: upright, wantWidth: 80, wantHeight: 120},
+		{name: "stored", client: stored, wantWidth: 120, wantHeight: 80},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			before := len(server.Requests())
+			for i := 0; i < 2; i++ {
+				if _, err := test.client.DetectObjects(context.Background(), image); err != nil {
+					t.Fatal(err)
+				}
+			}
+			requests := server.Requests()[before:]
+			if len(requests) != 1 {
+				t.Fatalf("requests = %v, want 1 with the second call cached", len(requests))
+			}
+			info, err := preprocess.Inspect(requests[0].Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Width != test.wantWidth || info.Height != test.wantHeight {
+				t.Errorf("uploaded %vx%v, want %vx%v", info.Width, info.Height, test.wantWidth, test.wantHeight)
+			}
+		})
+	}
+}
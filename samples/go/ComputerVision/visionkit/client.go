@@ -0,0 +1,96 @@
+package visionkit
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// Client runs the Computer Vision tasks against a single endpoint.
+type Client struct {
+	// BaseClient is the underlying SDK client. It is exported so callers can
+	// adjust the autorest settings (sender, inspectors, retries) directly.
+	BaseClient computervision.BaseClient
+
+	// Language is the output language of captions, tags, and categories.
+	// "" uses the service default ("en").
+	Language string
+
+	// Details are the domain-specific details (celebrities, landmarks) added
+	// to the categories of an image by Categorize and Analyze.
+	Details []computervision.Details
+
+	// ReadPoller waits for the operations started by ReadText.
+	ReadPoller ReadPoller
+
+	// Retry retries the calls that fail with a retryable status code or a
+	// network error, re-opening the image for each attempt.
+	Retry RetryPolicy
+
+	// Limiter, if set, is waited on before every HTTP request, read
+	// operation polls and retries included. See the ratelimit package.
+	Limiter Limiter
+
+	// Cache, if set, holds the results of earlier calls. A task whose image,
+	// parameters, Details, Language, and Preprocessor match an entry returns
+	// it without calling the service. See the cache package.
+	Cache Cache
+
+	// Preprocessor, if set, checks every local image before it is uploaded
+	// and shrinks or converts the ones the service would reject. The
+	// locations in the results are scaled back to the original image. See
+	// the preprocess package.
+	Preprocessor Preprocessor
+
+	// models holds the result of ListModels. It is nil for a Client not
+	// made by one of the New functions, which lists the models every time.
+	models *modelList
+}
+
+// Limiter paces the requests of a Client.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// New creates a Client for endpointURL, for example
+// "https://westus.api.cognitive.microsoft.com", and sets up the authorization
+// on it with the subscription key.
+func New(endpointURL, subscriptionKey string) *Client {
+	return NewWithAuthorizer(endpointURL, autorest.NewCognitiveServicesAuthorizer(subscriptionKey))
+}
+
+// NewWithAuthorizer creates a Client for endpointURL that authorizes its
+// requests with authorizer, for example one built by the auth package.
+func NewWithAuthorizer(endpointURL string, authorizer autorest.Authorizer) *Client {
+	baseClient := computervision.New(endpointURL)
+	baseClient.Authorizer = authorizer
+	return NewFromBaseClient(baseClient)
+}
+
+// NewFromBaseClient wraps an SDK client that has already been configured.
+// Unless baseClient has its own SendDecorators, the retries built into the
+// SDK are turned off, since they cannot re-open a consumed image stream and
+// would retry 429s without end; the client's Retry policy replaces them.
+// The client's Limiter goes inside any existing SendDecorators, so that it
+// also paces the requests they retry.
+func NewFromBaseClient(baseClient computervision.BaseClient) *Client {
+	client := &Client{models: &modelList{}}
+	baseClient.SendDecorators = append([]autorest.SendDecorator{client.waitForLimiter}, baseClient.SendDecorators...)
+	client.BaseClient = baseClient
+	return client
+}
+
+// waitForLimiter is a SendDecorator that waits on the Limiter, if any,
+// before each request.
+func (c *Client) waitForLimiter(s autorest.Sender) autorest.Sender {
+	return autorest.SenderFunc(func(r *http.Request) (*http.Response, error) {
+		if c.Limiter != nil {
+			if err := c.Limiter.Wait(r.Context()); err != nil {
+				return nil, err
+			}
+		}
+		return s.Do(r)
+	})
+}
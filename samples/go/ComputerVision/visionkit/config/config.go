@@ -0,0 +1,382 @@
+// Package config resolves the settings of a visionkit client from a config
+// file, the environment, and command-line flags.
+//
+// Each setting is taken from the first layer that sets it, in this order:
+//
+//  1. Flags, passed to Load as Options.Flags.
+//  2. Environment variables. The COMPUTERVISION_* names win over the
+//     AZURE_* names used by the older quickstarts (AZURE_COMPUTERVISION_API_KEY,
+//     AZURE_REGION, AZURE_ENDPOINT) when both are set.
+//  3. The selected profile of the config file.
+//  4. The defaults: the public cloud, every visual feature but Brands, the
+//     read poller's timeout, visionkit.DefaultConcurrency, a result cache
+//     in cache.DefaultDir kept for cache.DefaultTTL, and preprocessing on.
+//
+// The endpoint URL and the region count as one setting: the highest layer
+// that sets either of them wins, and within a layer the endpoint URL wins
+// over the region. A region from a flag therefore replaces an endpoint URL
+// from the config file. The credential works the same way: the highest layer
+// that sets a token command, a key file, or a key wins, in that order within
+// the layer.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/auth"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cache"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/preprocess"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/ratelimit"
+)
+
+// ErrNoKey is returned by Load when no layer sets a subscription key, a key
+// file, or a token command.
+var ErrNoKey = errors.New("config: no subscription key, key file, or token command configured")
+
+// Values of Settings.Cache besides a directory.
+const (
+	CacheMemory = "memory"
+	CacheOff    = "off"
+)
+
+// Values of Settings.Preprocess.
+const (
+	PreprocessOn  = "on"
+	PreprocessOff = "off"
+)
+
+// Settings are the values of one layer. The zero value of a field means the
+// layer leaves it unset.
+type Settings struct {
+	Endpoint string `yaml:"endpoint" toml:"endpoint"`
+	Region   string `yaml:"region" toml:"region"`
+	Cloud    string `yaml:"cloud" toml:"cloud"`
+	Key      string `yaml:"key" toml:"key"`
+	// KeyFile is a file holding the key, read again when it changes.
+	KeyFile string `yaml:"key_file" toml:"key_file"`
+	// TokenCommand prints an Azure AD bearer token (see auth.TokenCommand).
+	TokenCommand []string `yaml:"token_command" toml:"token_command"`
+	Language     string   `yaml:"language" toml:"language"`
+	Features     []string `yaml:"features" toml:"features"`
+	Details      []string `yaml:"details" toml:"details"`
+	// Timeout limits each HTTP request.
+	Timeout Duration `yaml:"timeout" toml:"timeout"`
+	// ReadTimeout limits the wait for a read operation.
+	ReadTimeout Duration `yaml:"read_timeout" toml:"read_timeout"`
+	Concurrency int      `yaml:"concurrency" toml:"concurrency"`
+	// MaxAttempts caps the attempts of each call (see
+	// visionkit.RetryPolicy); 1 turns retries off.
+	MaxAttempts int `yaml:"max_attempts" toml:"max_attempts"`
+	// RateLimit is the requests per second allowed to the endpoint, such as
+	// "10" or "20/m" (see ratelimit.ParseRate). Burst is the number of
+	// requests sent at once after a quiet period.
+	RateLimit string `yaml:"rate_limit" toml:"rate_limit"`
+	Burst     int    `yaml:"burst" toml:"burst"`
+	// Cache is the directory of the result cache, "memory" for a cache that
+	// lasts one run, or "off".
+	Cache    string   `yaml:"cache" toml:"cache"`
+	CacheTTL Duration `yaml:"cache_ttl" toml:"cache_ttl"`
+	// Preprocess is "on" to fit local images to the limits of the service
+	// before upload (see the preprocess package), or "off".
+	Preprocess string `yaml:"preprocess" toml:"preprocess"`
+}
+
+// Options tell Load where to look.
+type Options struct {
+	// Path is the config file. "" uses $COMPUTERVISION_CONFIG, then
+	// DefaultPath if that file exists.
+	Path string
+	// Profile is the profile to use. "" uses $COMPUTERVISION_PROFILE, then
+	// the default_profile of the file, then "default".
+	Profile string
+	// Flags is the highest layer.
+	Flags Settings
+	// Getenv reads the environment. Defaults to os.Getenv.
+	Getenv func(string) string
+}
+
+// Config is the resolved configuration.
+type Config struct {
+	// Path and Profile are the config file and profile used, if any.
+	Path    string
+	Profile string
+
+	EndpointURL string
+	// Only one of Key, KeyFile, and TokenCommand is set.
+	Key          string
+	KeyFile      string
+	TokenCommand []string
+	Language     string
+	// OCRLanguage is Language as a language of the OCR API, which names
+	// some languages differently: English when Language is unset, and
+	// computervision.Unk, to detect the language, when the OCR API does not
+	// know it.
+	OCRLanguage computervision.OcrLanguages
+	Features    []computervision.VisualFeatureTypes
+	Details     []computervision.Details
+	Timeout     time.Duration
+	ReadTimeout time.Duration
+	Concurrency int
+	MaxAttempts int
+	// RateLimit is in requests per second; 0 means no limit.
+	RateLimit float64
+	Burst     int
+	// Cache is a directory, CacheMemory, or CacheOff.
+	Cache    string
+	CacheTTL time.Duration
+	// Preprocess turns on the preprocess.Preprocessor.
+	Preprocess bool
+
+	// Warnings are problems that did not stop Load, such as the two names
+	// of an environment variable set to different values.
+	Warnings []string
+}
+
+// Load resolves the configuration.
+func Load(options Options) (*Config, error) {
+	getenv := options.Getenv
+	if getenv == nil {
+		getenv = osGetenv
+	}
+
+	config := &Config{}
+	file, err := loadFile(options.Path, getenv)
+	if err != nil {
+		return nil, err
+	}
+	profile := Settings{}
+	if file != nil {
+		config.Path = file.path
+		config.Profile, profile, err = file.profile(firstNonEmpty(options.Profile, getenv(EnvProfile)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	env, warnings := fromEnv(getenv)
+	config.Warnings = warnings
+
+	// Layers from the highest to the lowest.
+	layers := []Settings{options.Flags, env, profile}
+
+	endpoint := visionkit.Endpoint{}
+	for _, layer := range layers {
+		if layer.Endpoint != "" || layer.Region != "" {
+			endpoint = visionkit.Endpoint{URL: layer.Endpoint, Region: layer.Region}
+			break
+		}
+	}
+	for _, layer := range layers {
+		if layer.Cloud != "" {
+			endpoint.Cloud = layer.Cloud
+			break
+		}
+	}
+	if config.EndpointURL, err = visionkit.ResolveEndpoint(endpoint); err != nil {
+		return nil, err
+	}
+
+	for _, layer := range layers {
+		if len(layer.TokenCommand) > 0 {
+			config.TokenCommand = layer.TokenCommand
+			break
+		}
+		if layer.KeyFile != "" {
+			config.KeyFile = layer.KeyFile
+			break
+		}
+		if layer.Key != "" {
+			config.Key = layer.Key
+			break
+		}
+	}
+	if config.Key == "" && config.KeyFile == "" && len(config.TokenCommand) == 0 {
+		return nil, ErrNoKey
+	}
+
+	var features, details []string
+	var rateLimit string
+	preprocessing := PreprocessOn
+	for i := len(layers) - 1; i >= 0; i-- {
+		layer := layers[i]
+		if layer.Language != "" {
+			config.Language = layer.Language
+		}
+		if layer.Features != nil {
+			features = layer.Features
+		}
+		if layer.Details != nil {
+			details = layer.Details
+		}
+		if layer.Timeout != 0 {
+			config.Timeout = time.Duration(layer.Timeout)
+		}
+		if layer.ReadTimeout != 0 {
+			config.ReadTimeout = time.Duration(layer.ReadTimeout)
+		}
+		if layer.Concurrency != 0 {
+			config.Concurrency = layer.Concurrency
+		}
+		if layer.MaxAttempts != 0 {
+			config.MaxAttempts = layer.MaxAttempts
+		}
+		if layer.RateLimit != "" {
+			rateLimit = layer.RateLimit
+		}
+		if layer.Burst != 0 {
+			config.Burst = layer.Burst
+		}
+		if layer.Cache != "" {
+			config.Cache = layer.Cache
+		}
+		if layer.CacheTTL != 0 {
+			config.CacheTTL = time.Duration(layer.CacheTTL)
+		}
+		if layer.Preprocess != "" {
+			preprocessing = layer.Preprocess
+		}
+	}
+	var known bool
+	if config.OCRLanguage, known = ocrLanguage(config.Language); !known {
+		config.Warnings = append(config.Warnings, fmt.Sprintf("language %v is not an OCR language; ocr detects the language", config.Language))
+	}
+	if config.Features, err = visionkit.ParseFeatures(strings.Join(features, ",")); err != nil {
+		return nil, err
+	}
+	if config.Details, err = visionkit.ParseDetails(strings.Join(details, ",")); err != nil {
+		return nil, err
+	}
+	if config.Timeout < 0 || config.ReadTimeout < 0 || config.CacheTTL < 0 {
+		return nil, fmt.Errorf("config: timeouts must not be negative")
+	}
+	if config.Cache == "" {
+		config.Cache = cache.DefaultDir()
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = cache.DefaultTTL
+	}
+	switch strings.ToLower(preprocessing) {
+	case PreprocessOn:
+		config.Preprocess = true
+	case PreprocessOff:
+	default:
+		return nil, fmt.Errorf("config: preprocess must be %v or %v, not %q", PreprocessOn, PreprocessOff, preprocessing)
+	}
+	if rateLimit != "" {
+		if config.RateLimit, err = ratelimit.ParseRate(rateLimit); err != nil {
+			return nil, err
+		}
+	}
+	if config.Burst < 0 {
+		return nil, fmt.Errorf("config: burst must not be negative")
+	}
+	if config.MaxAttempts < 0 {
+		return nil, fmt.Errorf("config: max_attempts must not be negative")
+	}
+	if config.Concurrency < 0 {
+		return nil, fmt.Errorf("config: concurrency must not be negative")
+	} else if config.Concurrency == 0 {
+		config.Concurrency = visionkit.DefaultConcurrency
+	}
+	return config, nil
+}
+
+// Provider returns the credential provider of the configuration.
+func (c *Config) Provider() auth.Provider {
+	switch {
+	case len(c.TokenCommand) > 0:
+		return auth.NewTokenCommand(c.TokenCommand[0], c.TokenCommand[1:]...)
+	case c.KeyFile != "":
+		return auth.NewKeyFile(c.KeyFile)
+	}
+	return auth.StaticKey(c.Key)
+}
+
+// Client creates a visionkit client from the configuration.
+func (c *Config) Client() *visionkit.Client {
+	client := visionkit.NewWithAuthorizer(c.EndpointURL, auth.Authorizer(c.Provider()))
+	client.Language = c.Language
+	client.Details = c.Details
+	if c.Timeout > 0 {
+		client.BaseClient.Sender = &http.Client{Timeout: c.Timeout}
+	}
+	if c.ReadTimeout > 0 {
+		client.ReadPoller.Timeout = c.ReadTimeout
+	}
+	client.Retry.MaxAttempts = c.MaxAttempts
+	if c.RateLimit > 0 {
+		client.Limiter = ratelimit.Shared(c.EndpointURL, ratelimit.Limit{Rate: c.RateLimit, Burst: c.Burst})
+	}
+	if c.Preprocess {
+		client.Preprocessor = preprocess.Preprocessor{}
+	}
+	switch c.Cache {
+	case CacheOff:
+	case CacheMemory:
+		client.Cache = cache.NewMemory(cache.DefaultMemoryEntries, c.CacheTTL)
+	default:
+		client.Cache = cache.NewDir(c.Cache, c.CacheTTL)
+	}
+	return client
+}
+
+// SplitList splits a comma-separated flag value into the form of
+// Settings.Features and Settings.Details. An empty value returns nil, which
+// leaves the setting to the lower layers.
+func SplitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// ocrAliases are the OCR languages of language tags the OCR API does not
+// take as they are.
+var ocrAliases = map[string]computervision.OcrLanguages{
+	"zh":    computervision.ZhHans,
+	"zh-cn": computervision.ZhHans,
+	"zh-sg": computervision.ZhHans,
+	"zh-tw": computervision.ZhHant,
+	"zh-hk": computervision.ZhHant,
+	"zh-mo": computervision.ZhHant,
+	"no":    computervision.Nb,
+}
+
+// ocrLanguage returns the OCR language of a language tag, such as "zh-Hans"
+// for "zh" and "pt" for "pt-BR", and false with computervision.Unk if there
+// is none.
+func ocrLanguage(language string) (computervision.OcrLanguages, bool) {
+	if language == "" {
+		return computervision.En, true
+	}
+	for _, possible := range computervision.PossibleOcrLanguagesValues() {
+		if strings.EqualFold(string(possible), language) {
+			return possible, true
+		}
+	}
+	tag := strings.ToLower(language)
+	if alias, ok := ocrAliases[tag]; ok {
+		return alias, true
+	}
+	if i := strings.LastIndex(tag, "-"); i > 0 {
+		return ocrLanguage(tag[:i])
+	}
+	return computervision.Unk, false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,272 @@
+package config_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/config"
+)
+
+const yamlFile = `default_profile: work
+profiles:
+  default:
+    region: westus
+    key: file-default-key
+  work:
+    endpoint: https://work.cognitiveservices.azure.com
+    key_file: /run/secrets/key
+    language: es
+    features: [description, tags]
+    timeout: 30s
+    concurrency: 8
+    preprocess: "off"
+`
+
+const tomlFile = `[profiles.default]
+region = "eastus"
+key = "toml-key"
+read_timeout = "45s"
+cache = "memory"
+`
+
+// writeFile writes a config file into a temporary directory.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// env returns a Getenv that reads the given variables only.
+func env(variables map[string]string) func(string) string {
+	return func(name string) string { return variables[name] }
+}
+
+func TestLoadLayers(t *testing.T) {
+	yamlPath := writeFile(t, "config.yaml", yamlFile)
+	emptyPath := writeFile(t, "empty.yaml", "profiles: {}\n")
+
+	tests := []struct {
+		name    string
+		options config.Options
+		env     map[string]string
+		check   func(t *testing.T, c *config.Config)
+	}{
+		{
+			name:    "default profile of the file",
+			options: config.Options{Path: yamlPath},
+			check: func(t *testing.T, c *config.Config) {
+				if c.Profile != "work" || c.EndpointURL != "https://work.cognitiveservices.azure.com" || c.KeyFile != "/run/secrets/key" {
+					t.Errorf("profile %q endpoint %q key file %q, want the work profile", c.Profile, c.EndpointURL, c.KeyFile)
+				}
+				if c.Language != "es" || c.Timeout != 30*time.Second || c.Concurrency != 8 || c.Preprocess {
+					t.Errorf("language %q timeout %v concurrency %v preprocess %v, want the work profile", c.Language, c.Timeout, c.Concurrency, c.Preprocess)
+				}
+				want := []computervision.VisualFeatureTypes{computervision.VisualFeatureTypesDescription, computervision.VisualFeatureTypesTags}
+				if !reflect.DeepEqual(c.Features, want) {
+					t.Errorf("features = %v, want %v", c.Features, want)
+				}
+			},
+		},
+		{
+			name:    "profile from the environment",
+			options: config.Options{Path: yamlPath},
+			env:     map[string]string{config.EnvProfile: "default"},
+			check: func(t *testing.T, c *config.Config) {
+				if c.EndpointURL != "https://westus.api.cognitive.microsoft.com" || c.Key != "file-default-key" {
+					t.Errorf("endpoint %q key %q, want the default profile", c.EndpointURL, c.Key)
+				}
+				if !c.Preprocess || c.Concurrency != visionkit.DefaultConcurrency {
+					t.Errorf("preprocess %v concurrency %v, want the defaults", c.Preprocess, c.Concurrency)
+				}
+			},
+		},
+		{
+			name:    "environment over file",
+			options: config.Options{Path: yamlPath},
+			env:     map[string]string{config.EnvRegion: "northeurope", config.EnvKey: "env-key", config.EnvLanguage: "fr", config.EnvPreprocess: "on"},
+			check: func(t *testing.T, c *config.Config) {
+				if c.EndpointURL != "https://northeurope.api.cognitive.microsoft.com" {
+					t.Errorf("endpoint = %q, want the region of the environment to replace the endpoint of the file", c.EndpointURL)
+				}
+				if c.Key != "env-key" || c.KeyFile != "" {
+					t.Errorf("key %q key file %q, want only the key of the environment", c.Key, c.KeyFile)
+				}
+				if c.Language != "fr" || !c.Preprocess || c.Timeout != 30*time.Second {
+					t.Errorf("language %q preprocess %v timeout %v, want the environment and then the file", c.Language, c.Preprocess, c.Timeout)
+				}
+			},
+		},
+		{
+			name: "flags over environment",
+			options: config.Options{Path: yamlPath, Flags: config.Settings{
+				Endpoint:    "http://127.0.0.1:8080",
+				Key:         "flag-key",
+				Language:    "ja",
+				Concurrency: 2,
+				RateLimit:   "20/m",
+			}},
+			env: map[string]string{config.EnvEndpoint: "https://env.example.com", config.EnvTokenCommand: "az account get-access-token", config.EnvConcurrency: "4"},
+			check: func(t *testing.T, c *config.Config) {
+				if c.EndpointURL != "http://127.0.0.1:8080" || c.Key != "flag-key" || len(c.TokenCommand) != 0 {
+					t.Errorf("endpoint %q key %q token command %q, want the flags", c.EndpointURL, c.Key, c.TokenCommand)
+				}
+				if c.Language != "ja" || c.Concurrency != 2 || c.RateLimit != 20.0/60 {
+					t.Errorf("language %q concurrency %v rate %v, want the flags", c.Language, c.Concurrency, c.RateLimit)
+				}
+			},
+		},
+		{
+			name:    "token command wins within a layer",
+			options: config.Options{Path: emptyPath},
+			env:     map[string]string{config.EnvRegion: "westus", config.EnvKey: "env-key", config.EnvTokenCommand: "print-token --fresh"},
+			check: func(t *testing.T, c *config.Config) {
+				if !reflect.DeepEqual(c.TokenCommand, []string{"print-token", "--fresh"}) || c.Key != "" {
+					t.Errorf("token command %q key %q, want the token command only", c.TokenCommand, c.Key)
+				}
+			},
+		},
+		{
+			name:    "legacy names",
+			options: config.Options{Path: emptyPath},
+			env:     map[string]string{config.EnvLegacyRegion: "westus", config.EnvLegacyKey: "legacy-key", config.EnvKey: "new-key"},
+			check: func(t *testing.T, c *config.Config) {
+				if c.EndpointURL != "https://westus.api.cognitive.microsoft.com" || c.Key != "new-key" {
+					t.Errorf("endpoint %q key %q, want the legacy region and the new key", c.EndpointURL, c.Key)
+				}
+				if len(c.Warnings) != 1 {
+					t.Errorf("warnings = %q, want one about the two keys", c.Warnings)
+				}
+			},
+		},
+		{
+			name:    "toml",
+			options: config.Options{Path: writeFile(t, "config.toml", tomlFile)},
+			check: func(t *testing.T, c *config.Config) {
+				if c.EndpointURL != "https://eastus.api.cognitive.microsoft.com" || c.Key != "toml-key" || c.ReadTimeout != 45*time.Second || c.Cache != config.CacheMemory {
+					t.Errorf("endpoint %q key %q read timeout %v cache %q, want the TOML profile", c.EndpointURL, c.Key, c.ReadTimeout, c.Cache)
+				}
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.options.Getenv = env(test.env)
+			c, err := config.Load(test.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.check(t, c)
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	yamlPath := writeFile(t, "config.yaml", yamlFile)
+	emptyPath := writeFile(t, "empty.yaml", "profiles: {}\n")
+
+	tests := []struct {
+		name    string
+		options config.Options
+		env     map[string]string
+		wantErr error
+	}{
+		{name: "no endpoint", options: config.Options{Path: emptyPath, Flags: config.Settings{Key: "k"}}, wantErr: visionkit.ErrNoEndpoint},
+		{name: "no key", options: config.Options{Path: emptyPath, Flags: config.Settings{Region: "westus"}}, wantErr: config.ErrNoKey},
+		{name: "unknown profile", options: config.Options{Path: yamlPath, Profile: "home"}},
+		{name: "missing file", options: config.Options{Path: filepath.Join(filepath.Dir(emptyPath), "missing.yaml")}},
+		{name: "unknown field", options: config.Options{Path: writeFile(t, "typo.yaml", "profiles:\n  default:\n    regoin: westus\n")}},
+		{name: "bad feature", options: config.Options{Path: yamlPath, Flags: config.Settings{Features: []string{"colour"}}}},
+		{name: "bad preprocess", options: config.Options{Path: yamlPath, Flags: config.Settings{Preprocess: "maybe"}}},
+		{name: "negative concurrency", options: config.Options{Path: yamlPath, Flags: config.Settings{Concurrency: -1}}},
+		{name: "bad rate", options: config.Options{Path: yamlPath}, env: map[string]string{config.EnvRateLimit: "fast"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.options.Getenv = env(test.env)
+			_, err := config.Load(test.options)
+			if err == nil {
+				t.Fatal("Load succeeded, want an error")
+			}
+			if test.wantErr != nil && !errors.Is(err, test.wantErr) {
+				t.Errorf("error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadEnvWarnings(t *testing.T) {
+	path := writeFile(t, "empty.yaml", "profiles: {}\n")
+	c, err := config.Load(config.Options{Path: path, Getenv: env(map[string]string{
+		config.EnvRegion:      "westus",
+		config.EnvKey:         "k",
+		config.EnvTimeout:     "soon",
+		config.EnvConcurrency: "many",
+	})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Warnings) != 2 || c.Timeout != 0 || c.Concurrency != visionkit.DefaultConcurrency {
+		t.Errorf("warnings %q timeout %v concurrency %v, want the bad values ignored with a warning each", c.Warnings, c.Timeout, c.Concurrency)
+	}
+}
+
+func TestOCRLanguage(t *testing.T) {
+	path := writeFile(t, "empty.yaml", "profiles: {}\n")
+	tests := []struct {
+		language    string
+		want        computervision.OcrLanguages
+		wantWarning bool
+	}{
+		{"", computervision.En, false},
+		{"de", computervision.De, false},
+		{"ZH-HANT", computervision.ZhHant, false},
+		{"zh", computervision.ZhHans, false},
+		{"zh-TW", computervision.ZhHant, false},
+		{"pt-BR", computervision.Pt, false},
+		{"no", computervision.Nb, false},
+		{"hi", computervision.Unk, true},
+	}
+	for _, test := range tests {
+		c, err := config.Load(config.Options{Path: path, Flags: config.Settings{Region: "westus", Key: "k", Language: test.language}, Getenv: env(nil)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.OCRLanguage != test.want || (len(c.Warnings) > 0) != test.wantWarning {
+			t.Errorf("language %q: OCR language %q with warnings %q, want %q with a warning %v", test.language, c.OCRLanguage, c.Warnings, test.want, test.wantWarning)
+		}
+		if c.Language != test.language {
+			t.Errorf("language %q: Language = %q, want it unchanged for the other tasks", test.language, c.Language)
+		}
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", nil},
+		{"tags", []string{"tags"}},
+		{" tags, objects ,,faces ", []string{"tags", "objects", "faces"}},
+	}
+	for _, test := range tests {
+		if got := config.SplitList(test.value); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("SplitList(%q) = %q, want %q", test.value, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by Load. Where an older name is listed, it is
+// used only when the newer one is unset.
+const (
+	EnvConfig       = "COMPUTERVISION_CONFIG"
+	EnvProfile      = "COMPUTERVISION_PROFILE"
+	EnvEndpoint     = "COMPUTERVISION_ENDPOINT"
+	EnvRegion       = "COMPUTERVISION_REGION"
+	EnvCloud        = "COMPUTERVISION_CLOUD"
+	EnvKey          = "COMPUTERVISION_API_KEY"
+	EnvKeyFile      = "COMPUTERVISION_API_KEY_FILE"
+	EnvTokenCommand = "COMPUTERVISION_TOKEN_COMMAND"
+	EnvLanguage     = "COMPUTERVISION_LANGUAGE"
+	EnvFeatures     = "COMPUTERVISION_FEATURES"
+	EnvDetails      = "COMPUTERVISION_DETAILS"
+	EnvTimeout      = "COMPUTERVISION_TIMEOUT"
+	EnvReadTimeout  = "COMPUTERVISION_READ_TIMEOUT"
+	EnvConcurrency  = "COMPUTERVISION_CONCURRENCY"
+	EnvMaxAttempts  = "COMPUTERVISION_MAX_ATTEMPTS"
+	EnvRateLimit    = "COMPUTERVISION_RATE_LIMIT"
+	EnvBurst        = "COMPUTERVISION_BURST"
+	EnvCache        = "COMPUTERVISION_CACHE"
+	EnvCacheTTL     = "COMPUTERVISION_CACHE_TTL"
+	EnvPreprocess   = "COMPUTERVISION_PREPROCESS"
+
+	// The names used by the archived quickstarts and the Java samples.
+	EnvLegacyEndpoint = "AZURE_ENDPOINT"
+	EnvLegacyRegion   = "AZURE_REGION"
+	EnvLegacyKey      = "AZURE_COMPUTERVISION_API_KEY"
+)
+
+var osGetenv = os.Getenv
+
+// fromEnv reads the environment layer.
+func fromEnv(getenv func(string) string) (Settings, []string) {
+	var settings Settings
+	var warnings []string
+	lookup := func(name, legacy string) string {
+		value, legacyValue := getenv(name), getenv(legacy)
+		if value != "" && legacyValue != "" && value != legacyValue {
+			warnings = append(warnings, fmt.Sprintf("%v and %v differ; using %v", name, legacy, name))
+		}
+		return firstNonEmpty(value, legacyValue)
+	}
+
+	settings.Endpoint = lookup(EnvEndpoint, EnvLegacyEndpoint)
+	settings.Region = lookup(EnvRegion, EnvLegacyRegion)
+	settings.Key = lookup(EnvKey, EnvLegacyKey)
+	settings.KeyFile = getenv(EnvKeyFile)
+	settings.TokenCommand = strings.Fields(getenv(EnvTokenCommand))
+	settings.Cloud = getenv(EnvCloud)
+	settings.Language = getenv(EnvLanguage)
+	settings.Features = SplitList(getenv(EnvFeatures))
+	settings.Details = SplitList(getenv(EnvDetails))
+	settings.Timeout = envDuration(getenv, EnvTimeout, &warnings)
+	settings.ReadTimeout = envDuration(getenv, EnvReadTimeout, &warnings)
+	settings.Concurrency = envInt(getenv, EnvConcurrency, &warnings)
+	settings.MaxAttempts = envInt(getenv, EnvMaxAttempts, &warnings)
+	settings.RateLimit = getenv(EnvRateLimit)
+	settings.Burst = envInt(getenv, EnvBurst, &warnings)
+	settings.Cache = getenv(EnvCache)
+	settings.CacheTTL = envDuration(getenv, EnvCacheTTL, &warnings)
+	settings.Preprocess = getenv(EnvPreprocess)
+	return settings, warnings
+}
+
+func envInt(getenv func(string) string, name string, warnings *[]string) int {
+	value := getenv(name)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		*warnings = append(*warnings, fmt.Sprintf("ignoring %v: %v", name, err))
+		return 0
+	}
+	return n
+}
+
+func envDuration(getenv func(string) string, name string, warnings *[]string) Duration {
+	value := getenv(name)
+	if value == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		*warnings = append(*warnings, fmt.Sprintf("ignoring %v: %v", name, err))
+		return 0
+	}
+	return Duration(d)
+}
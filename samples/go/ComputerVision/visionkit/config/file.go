@@ -0,0 +1,126 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultProfile is the profile used when none is selected.
+const DefaultProfile = "default"
+
+// DefaultPath returns the config file used when none is given:
+// computervision/config.yaml in the user config directory (for example
+// ~/.config on Linux), or config.toml if only that one exists. It returns ""
+// if neither exists.
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	for _, name := range []string{"config.yaml", "config.yml", "config.toml"} {
+		path := filepath.Join(dir, "computervision", name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// File is a config file. Files ending in .toml are read as TOML; all others
+// as YAML. In YAML:
+//
+//	default_profile: work
+//	profiles:
+//	  default:
+//	    region: westus
+//	    key: 0123456789abcdef0123456789abcdef
+//	  work:
+//	    endpoint: https://my-resource.cognitiveservices.azure.com
+//	    key_file: /var/run/secrets/computervision/key
+//	    language: es
+//	    features: [description, tags, objects]
+//	    timeout: 30s
+//	    concurrency: 8
+type File struct {
+	DefaultProfile string              `yaml:"default_profile" toml:"default_profile"`
+	Profiles       map[string]Settings `yaml:"profiles" toml:"profiles"`
+
+	path string
+}
+
+// ReadFile reads and parses a config file.
+func ReadFile(path string) (*File, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	file := &File{path: path}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, file)
+	} else {
+		err = yaml.UnmarshalStrict(data, file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config: %v: %w", path, err)
+	}
+	return file, nil
+}
+
+// loadFile reads the file given, or named by the environment, or found at
+// DefaultPath. It returns nil if there is none.
+func loadFile(path string, getenv func(string) string) (*File, error) {
+	if path = firstNonEmpty(path, getenv(EnvConfig)); path != "" {
+		return ReadFile(path)
+	}
+	if path = DefaultPath(); path != "" {
+		return ReadFile(path)
+	}
+	return nil, nil
+}
+
+// profile returns the profile selected by name, or by the file. A profile
+// selected by name must exist; the default profile may be missing.
+func (f *File) profile(name string) (string, Settings, error) {
+	if name == "" {
+		name = firstNonEmpty(f.DefaultProfile, DefaultProfile)
+		if _, ok := f.Profiles[name]; !ok && name == DefaultProfile {
+			return "", Settings{}, nil
+		}
+	}
+	settings, ok := f.Profiles[name]
+	if !ok {
+		return "", Settings{}, fmt.Errorf("config: %v has no profile %q", f.path, name)
+	}
+	return name, settings, nil
+}
+
+// Duration is a time.Duration written as a string such as "30s" or "2m" in
+// config files.
+type Duration time.Duration
+
+// UnmarshalText parses the duration for TOML.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// UnmarshalYAML parses the duration for YAML.
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var text string
+	if err := unmarshal(&text); err != nil {
+		return errors.New("durations are written as strings such as \"30s\"")
+	}
+	return d.UnmarshalText([]byte(text))
+}
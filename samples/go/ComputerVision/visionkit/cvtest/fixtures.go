@@ -0,0 +1,151 @@
+package cvtest
+
+import "net/http"
+
+// The canned bodies below are trimmed responses of the real service for the
+// sample images in the resources directory.
+
+// AnalyzeBody is the canned body of RouteAnalyze, with every visual feature
+// and both domain details filled in.
+const AnalyzeBody = `{
+  "categories": [
+    {"name": "people_", "score": 0.8984375, "detail": {"celebrities": [{"name": "Satya Nadella", "confidence": 0.9999, "faceRectangle": {"left": 597, "top": 162, "width": 248, "height": 248}}]}},
+    {"name": "building_", "score": 0.5, "detail": {"landmarks": [{"name": "Space Needle", "confidence": 0.9987}]}}
+  ],
+  "adult": {"isAdultContent": false, "isRacyContent": false, "adultScore": 0.0093, "racyScore": 0.0115},
+  "color": {"dominantColorForeground": "Black", "dominantColorBackground": "Grey", "dominantColors": ["Black", "Grey"], "accentColor": "6D3E1F", "isBWImg": false},
+  "imageType": {"clipArtType": 0, "lineDrawingType": 0},
+  "tags": [
+    {"name": "person", "confidence": 0.9990},
+    {"name": "outdoor", "confidence": 0.9522, "hint": "setting"}
+  ],
+  "description": {
+    "tags": ["person", "outdoor", "man"],
+    "captions": [{"text": "a group of people posing for the camera", "confidence": 0.8732}]
+  },
+  "faces": [
+    {"age": 37, "gender": "Male", "faceRectangle": {"left": 597, "top": 162, "width": 248, "height": 248}},
+    {"age": 29, "gender": "Female", "faceRectangle": {"left": 97, "top": 228, "width": 204, "height": 204}}
+  ],
+  "objects": [
+    {"rectangle": {"x": 90, "y": 120, "w": 230, "h": 480}, "object": "person", "confidence": 0.879}
+  ],
+  "brands": [
+    {"name": "Microsoft", "confidence": 0.706, "rectangle": {"x": 58, "y": 113, "w": 109, "h": 100}}
+  ],
+  "requestId": "00000000-0000-0000-0000-000000000000",
+  "metadata": {"width": 1024, "height": 768, "format": "Jpeg"}
+}`
+
+// DescribeBody is the canned body of RouteDescribe.
+const DescribeBody = `{
+  "description": {
+    "tags": ["person", "outdoor", "man"],
+    "captions": [{"text": "a group of people posing for the camera", "confidence": 0.8732}]
+  },
+  "requestId": "00000000-0000-0000-0000-000000000000",
+  "metadata": {"width": 1024, "height": 768, "format": "Jpeg"}
+}`
+
+// TagBody is the canned body of RouteTag.
+const TagBody = `{
+  "tags": [
+    {"name": "person", "confidence": 0.9990},
+    {"name": "outdoor", "confidence": 0.9522, "hint": "setting"}
+  ],
+  "requestId": "00000000-0000-0000-0000-000000000000",
+  "metadata": {"width": 1024, "height": 768, "format": "Jpeg"}
+}`
+
+// DetectBody is the canned body of RouteDetect.
+const DetectBody = `{
+  "objects": [
+    {"rectangle": {"x": 90, "y": 120, "w": 230, "h": 480}, "object": "person", "confidence": 0.879},
+    {"rectangle": {"x": 400, "y": 300, "w": 120, "h": 90}, "object": "dog", "confidence": 0.701, "parent": {"object": "mammal", "confidence": 0.9, "parent": {"object": "animal", "confidence": 0.92}}}
+  ],
+  "requestId": "00000000-0000-0000-0000-000000000000",
+  "metadata": {"width": 1024, "height": 768, "format": "Jpeg"}
+}`
+
+// ModelsBody is the canned body of RouteModels.
+const ModelsBody = `{
+  "models": [
+    {"name": "celebrities", "categories": ["people_", "人_", "pessoas_", "gente_"]},
+    {"name": "landmarks", "categories": ["outdoor_", "户外_", "屋外_", "aoarlivre_", "alairelibre_", "building_", "建筑_", "建物_", "edifício_"]}
+  ],
+  "requestId": "00000000-0000-0000-0000-000000000000"
+}`
+
+// CelebritiesBody is the canned body of models/celebrities/analyze.
+const CelebritiesBody = `{
+  "result": {"celebrities": [{"name": "Satya Nadella", "confidence": 0.9999, "faceRectangle": {"left": 597, "top": 162, "width": 248, "height": 248}}]},
+  "requestId": "00000000-0000-0000-0000-000000000000",
+  "metadata": {"width": 1024, "height": 768, "format": "Jpeg"}
+}`
+
+// LandmarksBody is the canned body of models/landmarks/analyze.
+const LandmarksBody = `{
+  "result": {"landmarks": [{"name": "Space Needle", "confidence": 0.9987}]},
+  "requestId": "00000000-0000-0000-0000-000000000000",
+  "metadata": {"width": 1024, "height": 768, "format": "Jpeg"}
+}`
+
+// ReadOperationBody is the canned body of a read operation that succeeded.
+const ReadOperationBody = `{
+  "status": "Succeeded",
+  "recognitionResults": [
+    {
+      "page": 1,
+      "clockwiseOrientation": 0.38,
+      "width": 1000,
+      "height": 400,
+      "unit": "pixel",
+      "lines": [
+        {
+          "boundingBox": [12, 20, 480, 22, 480, 70, 12, 68],
+          "text": "Nutrition Facts",
+          "words": [
+            {"boundingBox": [12, 20, 230, 21, 230, 69, 12, 68], "text": "Nutrition"},
+            {"boundingBox": [250, 21, 480, 22, 480, 70, 250, 69], "text": "Facts", "confidence": "Low"}
+          ]
+        }
+      ]
+    }
+  ]
+}`
+
+// OCRBody is the canned body of RouteOCR.
+const OCRBody = `{
+  "language": "en",
+  "textAngle": 0,
+  "orientation": "Up",
+  "regions": [
+    {
+      "boundingBox": "12,20,468,50",
+      "lines": [
+        {
+          "boundingBox": "12,20,468,50",
+          "words": [
+            {"boundingBox": "12,20,218,49", "text": "Nutrition"},
+            {"boundingBox": "250,21,230,49", "text": "Facts"}
+          ]
+        }
+      ]
+    }
+  ]
+}`
+
+func cannedResponses() map[string]Response {
+	return map[string]Response{
+		RouteAnalyze:                 {Body: AnalyzeBody},
+		RouteDescribe:                {Body: DescribeBody},
+		RouteTag:                     {Body: TagBody},
+		RouteDetect:                  {Body: DetectBody},
+		RouteModels:                  {Body: ModelsBody},
+		RouteDomain + "/celebrities": {Body: CelebritiesBody},
+		RouteDomain + "/landmarks":   {Body: LandmarksBody},
+		RouteRead:                    {Status: http.StatusAccepted},
+		RouteReadOperation:           {Body: ReadOperationBody},
+		RouteOCR:                     {Body: OCRBody},
+	}
+}
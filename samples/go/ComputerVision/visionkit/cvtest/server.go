@@ -0,0 +1,355 @@
+// Package cvtest is an in-process fake of the Computer Vision v2.0 REST API
+// for testing code built on visionkit without a network connection or a
+// subscription key.
+//
+// A Server answers the analyze, describe, tag, detect, models,
+// models/{model}/analyze, read/core/asyncBatchAnalyze, read/operations/{id},
+// and ocr routes with canned responses. Tests can replace the canned
+// response of a route, queue one-off responses (for example a 429 followed
+// by a success), delay responses, and make read operations run for several
+// polls before they succeed:
+//
+//	server := cvtest.NewServer()
+//	defer server.Close()
+//	server.Enqueue(cvtest.RouteTag, cvtest.RateLimited(time.Second))
+//	client := server.Client()
+//	tags, err := client.Tag(ctx, visionkit.URL("https://example.com/dog.jpg"))
+package cvtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+)
+
+// APIPath is the path prefix of every route, after the endpoint URL.
+const APIPath = "/vision/v2.0"
+
+// Key is the subscription key the Server accepts by default.
+const Key = "cvtest-key"
+
+// Route names, used to script the responses of a route. RouteDomain covers
+// models/{model}/analyze for every model.
+const (
+	RouteAnalyze       = "analyze"
+	RouteDescribe      = "describe"
+	RouteTag           = "tag"
+	RouteDetect        = "detect"
+	RouteModels        = "models"
+	RouteDomain        = "models/analyze"
+	RouteRead          = "read"
+	RouteReadOperation = "read/operations"
+	RouteOCR           = "ocr"
+)
+
+// Response is a scripted response.
+type Response struct {
+	// Status is the HTTP status code. Defaults to 200 (202 for RouteRead).
+	Status int
+	// Header holds extra response headers, for example Retry-After.
+	Header http.Header
+	// Body is written as is if it is a string or a []byte, and marshaled to
+	// JSON otherwise. A nil Body writes nothing.
+	Body interface{}
+	// Delay holds the response back, or until the client gives up.
+	Delay time.Duration
+}
+
+// Request is a request received by the Server.
+type Request struct {
+	Route  string
+	Method string
+	Path   string
+	Query  map[string][]string
+	Header http.Header
+	Body   []byte
+}
+
+// Server is the fake Computer Vision service. Its fields may be changed
+// before the first request.
+type Server struct {
+	*httptest.Server
+
+	// Key is the subscription key expected in Ocp-Apim-Subscription-Key.
+	// "" accepts any request. Defaults to the Key constant.
+	Key string
+	// Token, if set, is a bearer token accepted in the Authorization header
+	// in place of the key.
+	Token string
+
+	// ReadPolls is the number of polls for which a new read operation
+	// reports "Running" before it reports its result.
+	ReadPolls int
+	// ReadDelay delays every read operation poll, to simulate a slow
+	// service.
+	ReadDelay time.Duration
+
+	mu         sync.Mutex
+	canned     map[string]Response
+	queued     map[string][]Response
+	requests   []Request
+	operations map[string]int
+	nextID     int
+}
+
+// NewServer starts a Server with the canned responses of fixtures.go. The
+// caller must Close it.
+func NewServer() *Server {
+	s := &Server{
+		Key:        Key,
+		canned:     cannedResponses(),
+		queued:     map[string][]Response{},
+		operations: map[string]int{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Endpoint returns the endpoint URL to give to visionkit.New or
+// computervision.New.
+func (s *Server) Endpoint() string { return s.URL }
+
+// Client returns a visionkit client for the Server. Retries are turned off
+// and the read poller waits a few milliseconds between polls, so scripted
+// failures surface at once. Set the client's Retry policy to test retries.
+func (s *Server) Client() *visionkit.Client {
+	client := visionkit.New(s.URL, s.Key)
+	client.Retry = visionkit.RetryPolicy{MaxAttempts: 1}
+	client.ReadPoller.Backoff = visionkit.Backoff{Initial: time.Millisecond, Max: 10 * time.Millisecond, Jitter: -1}
+	return client
+}
+
+// Handle replaces the canned response of route, used whenever no response
+// is queued. For RouteDomain, the response is used for every model.
+func (s *Server) Handle(route string, response Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.canned[route] = response
+}
+
+// Enqueue queues responses for route. Each one is used once, in order,
+// before the route goes back to its canned response.
+func (s *Server) Enqueue(route string, responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queued[route] = append(s.queued[route], responses...)
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// RequestCount returns the number of requests received on route.
+func (s *Server) RequestCount(route string) int {
+	count := 0
+	for _, request := range s.Requests() {
+		if request.Route == route {
+			count++
+		}
+	}
+	return count
+}
+
+// RateLimited is a 429 response with a Retry-After header.
+func RateLimited(retryAfter time.Duration) Response {
+	return Response{
+		Status: http.StatusTooManyRequests,
+		Header: http.Header{"Retry-After": {strconv.Itoa(int(retryAfter.Round(time.Second) / time.Second))}},
+		Body:   errorBody("429", "Rate limit is exceeded."),
+	}
+}
+
+// ServerError is a 5xx (or any other) error response with the body the
+// service sends with errors.
+func ServerError(status int) Response {
+	return Response{Status: status, Body: errorBody(strconv.Itoa(status), http.StatusText(status))}
+}
+
+func errorBody(code, message string) map[string]string {
+	return map[string]string{"code": code, "message": message, "requestId": "00000000-0000-0000-0000-000000000000"}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	route, param, ok := matchRoute(r.Method, r.URL.Path)
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Route:  route,
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	})
+	s.mu.Unlock()
+
+	if !ok {
+		writeResponse(w, r, Response{Status: http.StatusNotFound, Body: errorBody("NotFound", "Resource not found.")})
+		return
+	}
+	if !s.authorized(r) {
+		writeResponse(w, r, Response{Status: http.StatusUnauthorized, Body: errorBody("401", "Access denied due to invalid subscription key.")})
+		return
+	}
+
+	response, queued := s.dequeue(route)
+	switch {
+	case queued:
+	case route == RouteRead:
+		response = s.startRead(r)
+	case route == RouteReadOperation:
+		response = s.pollRead(param)
+	case route == RouteDomain:
+		response = s.domainResponse(param)
+	default:
+		response = s.cannedResponse(route)
+	}
+	writeResponse(w, r, response)
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	if s.Token != "" && r.Header.Get("Authorization") == "Bearer "+s.Token {
+		return true
+	}
+	return s.Key == "" || r.Header.Get("Ocp-Apim-Subscription-Key") == s.Key
+}
+
+// matchRoute maps a request to its route. param is the model of RouteDomain
+// and the operation ID of RouteReadOperation.
+func matchRoute(method, path string) (route, param string, ok bool) {
+	if !strings.HasPrefix(path, APIPath+"/") {
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimPrefix(path, APIPath+"/"), "/")
+	switch {
+	case method == http.MethodGet && len(parts) == 1 && parts[0] == "models":
+		return RouteModels, "", true
+	case method == http.MethodGet && len(parts) == 3 && parts[0] == "read" && parts[1] == "operations":
+		return RouteReadOperation, parts[2], true
+	case method != http.MethodPost:
+		return "", "", false
+	case len(parts) == 3 && parts[0] == "models" && parts[2] == "analyze":
+		return RouteDomain, parts[1], true
+	case len(parts) == 3 && parts[0] == "read" && parts[1] == "core" && parts[2] == "asyncBatchAnalyze":
+		return RouteRead, "", true
+	case len(parts) == 1:
+		switch parts[0] {
+		case RouteAnalyze, RouteDescribe, RouteTag, RouteDetect, RouteOCR:
+			return parts[0], "", true
+		}
+	}
+	return "", "", false
+}
+
+func (s *Server) dequeue(route string) (Response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queue := s.queued[route]
+	if len(queue) == 0 {
+		return Response{}, false
+	}
+	s.queued[route] = queue[1:]
+	return queue[0], true
+}
+
+func (s *Server) cannedResponse(route string) Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.canned[route]
+}
+
+// domainResponse answers celebrities and landmarks with their own canned
+// body, unless RouteDomain was given a response with Handle.
+func (s *Server) domainResponse(model string) Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if response, ok := s.canned[RouteDomain]; ok {
+		return response
+	}
+	if response, ok := s.canned[RouteDomain+"/"+model]; ok {
+		return response
+	}
+	return Response{Status: http.StatusNotFound, Body: errorBody("NotFound", "Model '"+model+"' not found.")}
+}
+
+func (s *Server) startRead(r *http.Request) Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	id := fmt.Sprintf("00000000-0000-0000-0000-%012d", s.nextID)
+	s.operations[id] = s.ReadPolls
+	response := s.canned[RouteRead]
+	header := http.Header{}
+	for name, values := range response.Header {
+		header[name] = values
+	}
+	header.Set("Operation-Location", s.URL+APIPath+"/read/operations/"+id)
+	response.Header = header
+	return response
+}
+
+func (s *Server) pollRead(id string) Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	remaining, ok := s.operations[id]
+	if !ok {
+		return Response{Status: http.StatusNotFound, Body: errorBody("NotFound", "Operation ID is not found.")}
+	}
+	if remaining > 0 {
+		s.operations[id] = remaining - 1
+		return Response{Body: map[string]string{"status": "Running"}, Delay: s.ReadDelay}
+	}
+	response := s.canned[RouteReadOperation]
+	response.Delay += s.ReadDelay
+	return response
+}
+
+func writeResponse(w http.ResponseWriter, r *http.Request, response Response) {
+	if response.Delay > 0 {
+		timer := time.NewTimer(response.Delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	var body []byte
+	switch b := response.Body.(type) {
+	case nil:
+	case string:
+		body = []byte(b)
+	case []byte:
+		body = b
+	default:
+		var err error
+		if body, err = json.Marshal(b); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if body != nil {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+	for name, values := range response.Header {
+		w.Header()[name] = values
+	}
+	status := response.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
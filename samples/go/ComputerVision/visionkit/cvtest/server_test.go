@@ -0,0 +1,132 @@
+package cvtest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+)
+
+var image = visionkit.URL("https://example.com/dog.jpg")
+
+func TestServerCannedResponses(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	tags, err := client.Tag(context.Background(), image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) == 0 {
+		t.Error("Tag returned no tags from the canned body")
+	}
+	requests := server.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("requests = %v, want 1", len(requests))
+	}
+	if request := requests[0]; request.Route != cvtest.RouteTag || request.Method != http.MethodPost || request.Header.Get("Ocp-Apim-Subscription-Key") != cvtest.Key {
+		t.Errorf("request = %v %v %v, want a keyed POST to the tag route", request.Method, request.Route, request.Header)
+	}
+}
+
+func TestServerEnqueue(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	server.Enqueue(cvtest.RouteTag, cvtest.ServerError(http.StatusInternalServerError), cvtest.ServerError(http.StatusBadGateway))
+	client := server.Client()
+
+	for _, want := range []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK} {
+		_, err := client.Tag(context.Background(), image)
+		if got := statusCode(err); got != want {
+			t.Errorf("status = %v (%v), want %v", got, err, want)
+		}
+	}
+}
+
+func TestServerAuthorization(t *testing.T) {
+	tests := []struct {
+		name       string
+		key, token string
+		client     func(server *cvtest.Server) *visionkit.Client
+		want       int
+	}{
+		{
+			name:   "key",
+			key:    cvtest.Key,
+			client: func(server *cvtest.Server) *visionkit.Client { return server.Client() },
+			want:   http.StatusOK,
+		},
+		{
+			name:   "wrong key",
+			key:    cvtest.Key,
+			client: func(server *cvtest.Server) *visionkit.Client { return visionkit.New(server.URL, "wrong") },
+			want:   http.StatusUnauthorized,
+		},
+		{
+			name:  "token",
+			key:   cvtest.Key,
+			token: "secret",
+			client: func(server *cvtest.Server) *visionkit.Client {
+				return visionkit.NewWithAuthorizer(server.URL, autorest.NewBearerAuthorizer(token("secret")))
+			},
+			want: http.StatusOK,
+		},
+		{
+			name:   "any key",
+			client: func(server *cvtest.Server) *visionkit.Client { return visionkit.New(server.URL, "anything") },
+			want:   http.StatusOK,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := cvtest.NewServer()
+			defer server.Close()
+			server.Key, server.Token = test.key, test.token
+			client := test.client(server)
+			client.Retry.MaxAttempts = 1
+
+			_, err := client.Tag(context.Background(), image)
+			if got := statusCode(err); got != test.want {
+				t.Errorf("status = %v (%v), want %v", got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestServerUnknownRoute(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+
+	response, err := http.Get(server.URL + cvtest.APIPath + "/nothing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %v, want %v", response.StatusCode, http.StatusNotFound)
+	}
+}
+
+// statusCode returns the HTTP status of a failed call, or 200 for nil.
+func statusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	var detailed autorest.DetailedError
+	if errors.As(err, &detailed) {
+		if code, ok := detailed.StatusCode.(int); ok {
+			return code
+		}
+	}
+	return 0
+}
+
+// token is a fixed bearer token.
+type token string
+
+func (t token) OAuthToken() string { return string(t) }
@@ -0,0 +1,30 @@
+// Package visionkit wraps the Computer Vision client from the Azure SDK for Go
+// so that the tasks shown in the Go quickstart can be imported by other
+// programs. Every task returns its result and an error instead of printing
+// or exiting, which leaves reporting up to the caller. Images are passed as
+// an ImageSource, so each task works the same way for local files, URLs,
+// readers, and in-memory bytes.
+//
+// The tasks covered are:
+// - Describing images
+// - Categorizing images
+// - Tagging images
+// - Detecting faces
+// - Detecting adult or racy content
+// - Detecting the color scheme
+// - Detecting domain-specific content (celebrities/landmarks)
+// - Detecting image types (clip art/line drawing)
+// - Detecting objects
+// - Detecting brands
+// - Recognizing printed and handwritten text with the batch read API
+// - Recognizing printed text with OCR
+//
+// NewPlan and Client.RunPlan find several of these at once, merging the
+// visual features into a single AnalyzeImage call. A Client with a Cache
+// returns the results of images it has seen before without calling the
+// service; see the cache package. A Client with a Preprocessor fits local
+// images to the limits of the service before uploading them; see the
+// preprocess package. The locations in the results are the rectangles and
+// polygons of the geometry package, and the tile
+// package analyzes very large images in overlapping tiles.
+package visionkit
@@ -0,0 +1,194 @@
+package visionkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+)
+
+// Names of the domain-specific models accepted by AnalyzeImageByDomain.
+const (
+	DomainCelebrities = "celebrities"
+	DomainLandmarks   = "landmarks"
+)
+
+// DomainItem is one thing recognized by a domain-specific model. Rectangle
+// is only set by models that locate faces, such as celebrities.
+type DomainItem struct {
+	Name       string         `json:"name"`
+	Confidence float64        `json:"confidence"`
+	Rectangle  *geometry.Rect `json:"rectangle,omitempty"`
+}
+
+// DomainResult is the result of AnalyzeByDomain for any model listed by
+// ListModels. Items are the entries of the list named after the model, or of
+// the only list in the result; Result keeps the whole result as returned by
+// the service for the fields a model adds. The locations in Items and
+// Metadata are in the original image; those in Result are in the image as it
+// was uploaded, which differ when a Preprocessor scaled it.
+type DomainResult struct {
+	Model    string          `json:"model"`
+	Items    []DomainItem    `json:"items"`
+	Metadata ImageMetadata   `json:"metadata"`
+	Result   json.RawMessage `json:"result"`
+}
+
+// Celebrities returns the items as celebrities.
+func (r DomainResult) Celebrities() []Celebrity {
+	celebrities := []Celebrity{}
+	for _, item := range r.Items {
+		celebrity := Celebrity{Name: item.Name, Confidence: item.Confidence}
+		if item.Rectangle != nil {
+			celebrity.Rectangle = *item.Rectangle
+		}
+		celebrities = append(celebrities, celebrity)
+	}
+	return celebrities
+}
+
+// Landmarks returns the items as landmarks.
+func (r DomainResult) Landmarks() []Landmark {
+	landmarks := []Landmark{}
+	for _, item := range r.Items {
+		landmarks = append(landmarks, Landmark{Name: item.Name, Confidence: item.Confidence})
+	}
+	return landmarks
+}
+
+// DetectCelebrities returns the celebrities recognized in an image.
+func (c *Client) DetectCelebrities(ctx context.Context, image ImageSource) ([]Celebrity, error) {
+	domainResult, err := c.AnalyzeByDomain(ctx, DomainCelebrities, image)
+	if err != nil {
+		return nil, err
+	}
+	return domainResult.Celebrities(), nil
+}
+
+// DetectLandmarks returns the landmarks recognized in an image.
+func (c *Client) DetectLandmarks(ctx context.Context, image ImageSource) ([]Landmark, error) {
+	domainResult, err := c.AnalyzeByDomain(ctx, DomainLandmarks, image)
+	if err != nil {
+		return nil, err
+	}
+	return domainResult.Landmarks(), nil
+}
+
+// AnalyzeByDomain runs a domain-specific model on an image and decodes the
+// result with DecodeDomainResult.
+func (c *Client) AnalyzeByDomain(ctx context.Context, model string, image ImageSource) (DomainResult, error) {
+	var domainResult DomainResult
+	err := c.cached(ctx, "domain", image, []string{model}, &domainResult, func(image ImageSource) error {
+		domainModelResults, err := c.analyzeByDomain(ctx, model, image)
+		if err != nil {
+			return err
+		}
+		domainResult, err = DecodeDomainResult(model, domainModelResults)
+		return err
+	})
+	if err != nil {
+		return DomainResult{}, wrapError(model, image, err)
+	}
+	return domainResult, nil
+}
+
+// DecodeDomainResult converts the untyped Result of a domain model response.
+// The SDK leaves it as an interface{}, so it is marshalled back into JSON and
+// decoded into the list named after the model, or else the only list in it.
+func DecodeDomainResult(model string, domainModelResults computervision.DomainModelResults) (DomainResult, error) {
+	domainResult := DomainResult{
+		Model:    model,
+		Items:    []DomainItem{},
+		Metadata: toImageMetadata(domainModelResults.Metadata),
+	}
+	if domainModelResults.Result == nil {
+		return domainResult, nil
+	}
+
+	data, err := json.Marshal(domainModelResults.Result)
+	if err != nil {
+		return DomainResult{}, fmt.Errorf("decoding the %v result: %w", model, err)
+	}
+	domainResult.Result = data
+
+	var lists map[string]json.RawMessage
+	if err := json.Unmarshal(data, &lists); err != nil {
+		return DomainResult{}, fmt.Errorf("decoding the %v result: %w", model, err)
+	}
+	list, ok := lists[model]
+	if !ok {
+		list = onlyList(lists)
+	}
+	if list == nil {
+		return domainResult, nil
+	}
+
+	if domainResult.Items, err = decodeDomainItems(model, list); err != nil {
+		return DomainResult{}, fmt.Errorf("decoding the %v result: %w", model, err)
+	}
+	return domainResult, nil
+}
+
+// decodeDomainItems decodes the list of a domain model result. Celebrities
+// carry the rectangle of their face; landmarks, and the items of models the
+// SDK has no type for, only a name and a confidence.
+func decodeDomainItems(model string, list json.RawMessage) ([]DomainItem, error) {
+	domainItems := []DomainItem{}
+	if model == DomainCelebrities {
+		var items []computervision.CelebritiesModel
+		if err := json.Unmarshal(list, &items); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			domainItem := DomainItem{Name: stringValue(item.Name), Confidence: float64Value(item.Confidence)}
+			if item.FaceRectangle != nil {
+				rectangle := toFaceRectangle(item.FaceRectangle)
+				domainItem.Rectangle = &rectangle
+			}
+			domainItems = append(domainItems, domainItem)
+		}
+		return domainItems, nil
+	}
+
+	var items []computervision.LandmarksModel
+	if err := json.Unmarshal(list, &items); err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		domainItems = append(domainItems, DomainItem{Name: stringValue(item.Name), Confidence: float64Value(item.Confidence)})
+	}
+	return domainItems, nil
+}
+
+// onlyList returns the only JSON array among the fields of a result, or nil
+// if there are none or several.
+func onlyList(fields map[string]json.RawMessage) json.RawMessage {
+	var list json.RawMessage
+	for _, field := range fields {
+		if trimmed := bytes.TrimSpace(field); len(trimmed) == 0 || trimmed[0] != '[' {
+			continue
+		}
+		if list != nil {
+			return nil
+		}
+		list = field
+	}
+	return list
+}
+
+// analyzeByDomain calls AnalyzeImageByDomain for remote images, or
+// AnalyzeImageByDomainInStream for everything else, with the model name.
+func (c *Client) analyzeByDomain(ctx context.Context, model string, image ImageSource) (domainModelResults computervision.DomainModelResults, err error) {
+	err = c.call(ctx, image, func(imageURL computervision.ImageURL) (err error) {
+		domainModelResults, err = c.BaseClient.AnalyzeImageByDomain(ctx, model, imageURL, c.Language)
+		return err
+	}, func(localImage io.ReadCloser) (err error) {
+		domainModelResults, err = c.BaseClient.AnalyzeImageByDomainInStream(ctx, model, localImage, c.Language)
+		return err
+	})
+	return domainModelResults, err
+}
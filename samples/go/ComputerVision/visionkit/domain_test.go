@@ -0,0 +1,130 @@
+package visionkit_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+)
+
+func TestDecodeDomainResult(t *testing.T) {
+	tests := []struct {
+		name      string
+		model     string
+		result    string
+		wantItems []visionkit.DomainItem
+		wantErr   bool
+	}{
+		{
+			name:   "celebrities",
+			model:  visionkit.DomainCelebrities,
+			result: `{"celebrities": [{"name": "Satya Nadella", "confidence": 0.99, "faceRectangle": {"left": 597, "top": 162, "width": 248, "height": 248}}]}`,
+			wantItems: []visionkit.DomainItem{
+				{Name: "Satya Nadella", Confidence: 0.99, Rectangle: &geometry.Rect{X: 597, Y: 162, W: 248, H: 248}},
+			},
+		},
+		{
+			name:      "landmarks",
+			model:     visionkit.DomainLandmarks,
+			result:    `{"landmarks": [{"name": "Space Needle", "confidence": 0.98}, {"name": "Eiffel Tower", "confidence": 0.5}]}`,
+			wantItems: []visionkit.DomainItem{{Name: "Space Needle", Confidence: 0.98}, {Name: "Eiffel Tower", Confidence: 0.5}},
+		},
+		{
+			name:      "landmarks are not located",
+			model:     visionkit.DomainLandmarks,
+			result:    `{"landmarks": [{"name": "Space Needle", "confidence": 0.98, "faceRectangle": {"left": 1, "top": 2, "width": 3, "height": 4}}]}`,
+			wantItems: []visionkit.DomainItem{{Name: "Space Needle", Confidence: 0.98}},
+		},
+		{
+			name:      "only list of a new model",
+			model:     "plants",
+			result:    `{"species": [{"name": "Fern", "confidence": 0.7}]}`,
+			wantItems: []visionkit.DomainItem{{Name: "Fern", Confidence: 0.7}},
+		},
+		{
+			name:      "no list named after the model",
+			model:     "plants",
+			result:    `{"species": [], "genera": []}`,
+			wantItems: []visionkit.DomainItem{},
+		},
+		{name: "no result", model: "plants", wantItems: []visionkit.DomainItem{}},
+		{name: "not a list", model: visionkit.DomainLandmarks, result: `{"landmarks": "Space Needle"}`, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var domainModelResults computervision.DomainModelResults
+			if test.result != "" {
+				json.Unmarshal([]byte(test.result), &domainModelResults.Result)
+			}
+			got, err := visionkit.DecodeDomainResult(test.model, domainModelResults)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("DecodeDomainResult = %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Model != test.model || !reflect.DeepEqual(got.Items, test.wantItems) {
+				t.Errorf("DecodeDomainResult = %v %+v, want %v %+v", got.Model, got.Items, test.model, test.wantItems)
+			}
+			if test.result != "" && len(got.Result) == 0 {
+				t.Error("Result is empty, want the result of the service")
+			}
+		})
+	}
+}
+
+func TestDetectCelebritiesAndLandmarks(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	celebrities, err := client.DetectCelebrities(context.Background(), image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []visionkit.Celebrity{{Name: "Satya Nadella", Confidence: 0.9999, Rectangle: geometry.Rect{X: 597, Y: 162, W: 248, H: 248}}}
+	if !reflect.DeepEqual(celebrities, want) {
+		t.Errorf("DetectCelebrities = %+v, want %+v", celebrities, want)
+	}
+
+	landmarks, err := client.DetectLandmarks(context.Background(), image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(landmarks) != 1 || landmarks[0].Name != "Space Needle" || landmarks[0].Confidence != 0.9987 {
+		t.Errorf("DetectLandmarks = %+v, want the Space Needle", landmarks)
+	}
+}
+
+func TestAnalyzeByDomain(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	server.Handle(cvtest.RouteDomain, cvtest.Response{Body: `{
+  "result": {"species": [{"name": "Fern", "confidence": 0.7}], "habitat": "forest"},
+  "metadata": {"width": 640, "height": 480, "format": "Png"}
+}`})
+	client := server.Client()
+
+	result, err := client.AnalyzeByDomain(context.Background(), "plants", image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 1 || result.Items[0].Name != "Fern" || result.Metadata.Width != 640 {
+		t.Errorf("AnalyzeByDomain = %+v, want the fern of a 640x480 image", result)
+	}
+	var extra struct{ Habitat string }
+	if err := json.Unmarshal(result.Result, &extra); err != nil || extra.Habitat != "forest" {
+		t.Errorf("Result = %s, want the fields the model adds kept", result.Result)
+	}
+	if requests := server.Requests(); len(requests) != 1 || requests[0].Path != cvtest.APIPath+"/models/plants/analyze" {
+		t.Errorf("requests = %+v, want one to the plants model", requests)
+	}
+}
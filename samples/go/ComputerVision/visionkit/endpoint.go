@@ -0,0 +1,115 @@
+package visionkit
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Errors returned by ResolveEndpoint.
+var (
+	// ErrNoEndpoint means neither an endpoint URL nor a region was given.
+	ErrNoEndpoint = errors.New("visionkit: no endpoint URL or region configured")
+	// ErrInvalidEndpoint means the endpoint URL, region, or cloud is not
+	// valid.
+	ErrInvalidEndpoint = errors.New("visionkit: invalid endpoint")
+)
+
+// Cloud is an Azure cloud. The endpoint of a region in the cloud is
+// "https://{region}." + EndpointSuffix.
+type Cloud struct {
+	Name           string
+	EndpointSuffix string
+}
+
+// Clouds are the clouds known to ResolveEndpoint, by lower-case name. More
+// can be added, for example for an Azure Stack deployment.
+var Clouds = map[string]Cloud{
+	"public":  {Name: "AzureCloud", EndpointSuffix: "api.cognitive.microsoft.com"},
+	"usgov":   {Name: "AzureUSGovernment", EndpointSuffix: "api.cognitive.microsoft.us"},
+	"china":   {Name: "AzureChinaCloud", EndpointSuffix: "api.cognitive.azure.cn"},
+	"germany": {Name: "AzureGermanCloud", EndpointSuffix: "api.cognitive.microsoft.de"},
+}
+
+// DefaultCloud is the cloud used when Endpoint.Cloud is empty.
+const DefaultCloud = "public"
+
+// Endpoint describes where the service is. URL takes precedence; the region
+// and cloud are only used when URL is empty.
+type Endpoint struct {
+	// URL is the full endpoint URL, for example the custom subdomain
+	// "https://my-resource.cognitiveservices.azure.com", a private endpoint,
+	// or a local stand-in such as "http://127.0.0.1:8080".
+	URL string
+	// Region is the Azure region, for example "westus" or "West US".
+	Region string
+	// Cloud is the name of a cloud in Clouds, or the Name of one of them
+	// (for example "AzureUSGovernment"). Defaults to DefaultCloud.
+	Cloud string
+}
+
+// ResolveEndpoint validates e and returns the endpoint URL to pass to New or
+// computervision.New, without a trailing slash.
+func ResolveEndpoint(e Endpoint) (string, error) {
+	if rawURL := strings.TrimSpace(e.URL); rawURL != "" {
+		return validateEndpointURL(rawURL)
+	}
+
+	region := strings.ToLower(strings.Join(strings.Fields(e.Region), ""))
+	if region == "" {
+		return "", ErrNoEndpoint
+	}
+	for _, r := range region {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return "", fmt.Errorf("%w: region %q may only contain letters and digits", ErrInvalidEndpoint, e.Region)
+		}
+	}
+
+	cloud, err := lookupCloud(e.Cloud)
+	if err != nil {
+		return "", err
+	}
+	return "https://" + region + "." + cloud.EndpointSuffix, nil
+}
+
+func validateEndpointURL(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidEndpoint, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%w: %s is not an absolute http(s) URL", ErrInvalidEndpoint, rawURL)
+	}
+	if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("%w: %s must not have credentials, a query, or a fragment", ErrInvalidEndpoint, rawURL)
+	}
+	// The SDK appends /vision/v2.0 itself, so a URL copied from a request
+	// would end up with the path twice.
+	if strings.Contains(strings.ToLower(u.Path), "/vision/") {
+		return "", fmt.Errorf("%w: %s must not include the /vision/... API path", ErrInvalidEndpoint, rawURL)
+	}
+	return strings.TrimRight(u.String(), "/"), nil
+}
+
+func lookupCloud(name string) (Cloud, error) {
+	if name = strings.TrimSpace(name); name == "" {
+		name = DefaultCloud
+	}
+	if cloud, ok := Clouds[strings.ToLower(name)]; ok {
+		return cloud, nil
+	}
+	for _, cloud := range Clouds {
+		if strings.EqualFold(cloud.Name, name) {
+			return cloud, nil
+		}
+	}
+
+	names := make([]string, 0, len(Clouds))
+	for key := range Clouds {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	return Cloud{}, fmt.Errorf("%w: unknown cloud %q (want one of %v)", ErrInvalidEndpoint, name, strings.Join(names, ", "))
+}
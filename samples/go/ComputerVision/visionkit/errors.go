@@ -0,0 +1,27 @@
+package visionkit
+
+// Error records a failed task together with the image it was run on, so a
+// caller working through many images can report the failure and move on.
+type Error struct {
+	// Op is the task that failed, for example "describe" or "read".
+	Op string
+	// Image is the Name of the image source.
+	Image string
+	// Err is the underlying error, usually an autorest.DetailedError.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return "visionkit: " + e.Op + " " + e.Image + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error { return e.Err }
+
+// wrapError returns nil if err is nil, and an *Error otherwise.
+func wrapError(op string, image ImageSource, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Op: op, Image: image.Name(), Err: err}
+}
@@ -0,0 +1,254 @@
+// Package geometry is the one representation of the locations returned by
+// the Computer Vision service. Every result type of visionkit holds a Rect
+// or a Polygon, whatever form the service used: x, y, w, h for objects and
+// brands, left, top, width, height for faces, an "x,y,w,h" string for OCR,
+// and eight corner coordinates for the Read API.
+//
+// Coordinates are in pixels of the image as the service saw it, with the
+// origin at the top-left corner, unless they have been made relative to the
+// image size with Relative.
+package geometry
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Point is a position in an image.
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Rect is an axis-aligned rectangle with its top-left corner at X, Y. Its
+// JSON form is {"x", "y", "w", "h"}, the one the service uses for objects
+// and brands.
+type Rect struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	W float64 `json:"w"`
+	H float64 `json:"h"`
+}
+
+// FromCorners returns the rectangle from the top-left corner x1, y1 to the
+// bottom-right corner x2, y2, in either order.
+func FromCorners(x1, y1, x2, y2 float64) Rect {
+	return Rect{
+		X: math.Min(x1, x2),
+		Y: math.Min(y1, y2),
+		W: math.Abs(x2 - x1),
+		H: math.Abs(y2 - y1),
+	}
+}
+
+// ParseRect parses the "x,y,w,h" bounding boxes of OCR results.
+func ParseRect(s string) (Rect, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return Rect{}, fmt.Errorf("geometry: bounding box %q does not have four values", s)
+	}
+	var values [4]float64
+	for i, part := range parts {
+		value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return Rect{}, fmt.Errorf("geometry: bounding box %q: %w", s, err)
+		}
+		values[i] = value
+	}
+	return Rect{X: values[0], Y: values[1], W: values[2], H: values[3]}, nil
+}
+
+// Min returns the top-left corner.
+func (r Rect) Min() Point { return Point{X: r.X, Y: r.Y} }
+
+// Max returns the bottom-right corner.
+func (r Rect) Max() Point { return Point{X: r.X + r.W, Y: r.Y + r.H} }
+
+// Center returns the center of the rectangle.
+func (r Rect) Center() Point { return Point{X: r.X + r.W/2, Y: r.Y + r.H/2} }
+
+// Area returns the area, which is 0 for an empty rectangle.
+func (r Rect) Area() float64 {
+	if r.Empty() {
+		return 0
+	}
+	return r.W * r.H
+}
+
+// Empty reports whether the rectangle has no area.
+func (r Rect) Empty() bool { return r.W <= 0 || r.H <= 0 }
+
+// Intersect returns the largest rectangle inside both r and s, which is the
+// zero Rect if they do not overlap.
+func (r Rect) Intersect(s Rect) Rect {
+	x1, y1 := math.Max(r.X, s.X), math.Max(r.Y, s.Y)
+	x2, y2 := math.Min(r.X+r.W, s.X+s.W), math.Min(r.Y+r.H, s.Y+s.H)
+	if x2 <= x1 || y2 <= y1 {
+		return Rect{}
+	}
+	return Rect{X: x1, Y: y1, W: x2 - x1, H: y2 - y1}
+}
+
+// Union returns the smallest rectangle that contains both r and s. An empty
+// rectangle adds nothing.
+func (r Rect) Union(s Rect) Rect {
+	if r.Empty() {
+		return s
+	}
+	if s.Empty() {
+		return r
+	}
+	x1, y1 := math.Min(r.X, s.X), math.Min(r.Y, s.Y)
+	x2, y2 := math.Max(r.X+r.W, s.X+s.W), math.Max(r.Y+r.H, s.Y+s.H)
+	return Rect{X: x1, Y: y1, W: x2 - x1, H: y2 - y1}
+}
+
+// IoU returns the intersection over union of two rectangles, from 0 when
+// they do not overlap to 1 when they are the same.
+func IoU(a, b Rect) float64 {
+	intersection := a.Intersect(b).Area()
+	if intersection == 0 {
+		return 0
+	}
+	return intersection / (a.Area() + b.Area() - intersection)
+}
+
+// Clip returns the part of the rectangle inside an image of the given size.
+func (r Rect) Clip(width, height float64) Rect {
+	return r.Intersect(Rect{W: width, H: height})
+}
+
+// Scale multiplies the coordinates by sx horizontally and sy vertically, for
+// example to map a rectangle found in a resized image back to the original.
+func (r Rect) Scale(sx, sy float64) Rect {
+	return Rect{X: r.X * sx, Y: r.Y * sy, W: r.W * sx, H: r.H * sy}
+}
+
+// Translate moves the rectangle by dx, dy.
+func (r Rect) Translate(dx, dy float64) Rect {
+	return Rect{X: r.X + dx, Y: r.Y + dy, W: r.W, H: r.H}
+}
+
+// Relative returns the rectangle as fractions of an image of the given size,
+// from 0 to 1.
+func (r Rect) Relative(width, height float64) Rect {
+	if width <= 0 || height <= 0 {
+		return Rect{}
+	}
+	return r.Scale(1/width, 1/height)
+}
+
+// Absolute is the inverse of Relative.
+func (r Rect) Absolute(width, height float64) Rect {
+	return r.Scale(width, height)
+}
+
+// Polygon returns the four corners, clockwise from the top-left one.
+func (r Rect) Polygon() Polygon {
+	return Polygon{
+		{X: r.X, Y: r.Y},
+		{X: r.X + r.W, Y: r.Y},
+		{X: r.X + r.W, Y: r.Y + r.H},
+		{X: r.X, Y: r.Y + r.H},
+	}
+}
+
+// Image returns the rectangle rounded to whole pixels.
+func (r Rect) Image() image.Rectangle {
+	return image.Rect(round(r.X), round(r.Y), round(r.X+r.W), round(r.Y+r.H))
+}
+
+// String formats the rectangle as its top-left and bottom-right corners,
+// "(x1, y1), (x2, y2)".
+func (r Rect) String() string {
+	return fmt.Sprintf("(%v, %v), (%v, %v)", format(r.X), format(r.Y), format(r.X+r.W), format(r.Y+r.H))
+}
+
+// Polygon is a closed shape given by its corners in order, such as the
+// rotated box of a line of text.
+type Polygon []Point
+
+// FromCoordinates returns the polygon of the x, y pairs the Read API uses
+// for bounding boxes. A trailing odd value is ignored.
+func FromCoordinates(coordinates []int) Polygon {
+	polygon := make(Polygon, 0, len(coordinates)/2)
+	for i := 0; i+1 < len(coordinates); i += 2 {
+		polygon = append(polygon, Point{X: float64(coordinates[i]), Y: float64(coordinates[i+1])})
+	}
+	return polygon
+}
+
+// Bounds returns the smallest rectangle around the polygon.
+func (p Polygon) Bounds() Rect {
+	if len(p) == 0 {
+		return Rect{}
+	}
+	x1, y1, x2, y2 := p[0].X, p[0].Y, p[0].X, p[0].Y
+	for _, point := range p[1:] {
+		x1, y1 = math.Min(x1, point.X), math.Min(y1, point.Y)
+		x2, y2 = math.Max(x2, point.X), math.Max(y2, point.Y)
+	}
+	return FromCorners(x1, y1, x2, y2)
+}
+
+// Area returns the area of the polygon, with the shoelace formula.
+func (p Polygon) Area() float64 {
+	area := 0.0
+	for i := range p {
+		j := (i + 1) % len(p)
+		area += p[i].X*p[j].Y - p[j].X*p[i].Y
+	}
+	return math.Abs(area) / 2
+}
+
+// Scale multiplies the coordinates of every corner by sx and sy.
+func (p Polygon) Scale(sx, sy float64) Polygon {
+	scaled := make(Polygon, len(p))
+	for i, point := range p {
+		scaled[i] = Point{X: point.X * sx, Y: point.Y * sy}
+	}
+	return scaled
+}
+
+// Clip moves every corner inside an image of the given size.
+func (p Polygon) Clip(width, height float64) Polygon {
+	clipped := make(Polygon, len(p))
+	for i, point := range p {
+		clipped[i] = Point{X: clamp(point.X, 0, width), Y: clamp(point.Y, 0, height)}
+	}
+	return clipped
+}
+
+// Relative returns the polygon as fractions of an image of the given size.
+func (p Polygon) Relative(width, height float64) Polygon {
+	if width <= 0 || height <= 0 {
+		return Polygon{}
+	}
+	return p.Scale(1/width, 1/height)
+}
+
+// String formats the corners as "(x1, y1), (x2, y2), ...".
+func (p Polygon) String() string {
+	points := make([]string, len(p))
+	for i, point := range p {
+		points[i] = fmt.Sprintf("(%v, %v)", format(point.X), format(point.Y))
+	}
+	return strings.Join(points, ", ")
+}
+
+func clamp(value, min, max float64) float64 {
+	return math.Max(min, math.Min(value, max))
+}
+
+func round(value float64) int {
+	return int(math.Round(value))
+}
+
+// format writes whole numbers without a fraction and others with at most
+// three decimals.
+func format(value float64) string {
+	return strconv.FormatFloat(math.Round(value*1000)/1000, 'f', -1, 64)
+}
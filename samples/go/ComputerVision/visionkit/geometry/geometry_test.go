@@ -0,0 +1,128 @@
+package geometry_test
+
+import (
+	"encoding/json"
+	"image"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+)
+
+func TestParseRect(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    geometry.Rect
+		wantErr bool
+	}{
+		{s: "12,34,56,78", want: geometry.Rect{X: 12, Y: 34, W: 56, H: 78}},
+		{s: " 1.5, 2 ,3,4 ", want: geometry.Rect{X: 1.5, Y: 2, W: 3, H: 4}},
+		{s: "", wantErr: true},
+		{s: "1,2,3", wantErr: true},
+		{s: "1,2,3,four", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := geometry.ParseRect(test.s)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("ParseRect(%q) = %v, %v, want %v", test.s, got, err, test.want)
+		}
+	}
+}
+
+func TestRectOperations(t *testing.T) {
+	a := geometry.Rect{X: 0, Y: 0, W: 10, H: 10}
+	b := geometry.Rect{X: 5, Y: 5, W: 10, H: 10}
+	far := geometry.Rect{X: 100, Y: 100, W: 1, H: 1}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"from corners in any order", geometry.FromCorners(10, 20, 0, 5), geometry.Rect{X: 0, Y: 5, W: 10, H: 15}},
+		{"max", b.Max(), geometry.Point{X: 15, Y: 15}},
+		{"center", b.Center(), geometry.Point{X: 10, Y: 10}},
+		{"area", a.Area(), 100.0},
+		{"area of empty", geometry.Rect{W: -1, H: 5}.Area(), 0.0},
+		{"intersect", a.Intersect(b), geometry.Rect{X: 5, Y: 5, W: 5, H: 5}},
+		{"intersect apart", a.Intersect(far), geometry.Rect{}},
+		{"union", a.Union(b), geometry.Rect{W: 15, H: 15}},
+		{"union with empty", geometry.Rect{}.Union(b), b},
+		{"iou", geometry.IoU(a, b), 25.0 / 175},
+		{"iou same", geometry.IoU(a, a), 1.0},
+		{"iou apart", geometry.IoU(a, far), 0.0},
+		{"clip", b.Clip(12, 8), geometry.Rect{X: 5, Y: 5, W: 7, H: 3}},
+		{"scale", b.Scale(2, 0.5), geometry.Rect{X: 10, Y: 2.5, W: 20, H: 5}},
+		{"translate", b.Translate(-5, 1), geometry.Rect{X: 0, Y: 6, W: 10, H: 10}},
+		{"relative", b.Relative(20, 50), geometry.Rect{X: 0.25, Y: 0.1, W: 0.5, H: 0.2}},
+		{"relative to nothing", b.Relative(0, 50), geometry.Rect{}},
+		{"absolute", geometry.Rect{X: 0.25, Y: 0.1, W: 0.5, H: 0.2}.Absolute(20, 50), b},
+		{"image", geometry.Rect{X: 0.4, Y: 1.6, W: 2.2, H: 2.2}.Image(), image.Rect(0, 2, 3, 4)},
+		{"string", geometry.Rect{X: 1, Y: 2.25, W: 3, H: 1.0 / 3}.String(), "(1, 2.25), (4, 2.583)"},
+	}
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("%v = %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	// A 4x2 box turned by 90 degrees, as the Read API gives it.
+	polygon := geometry.FromCoordinates([]int{6, 2, 6, 6, 4, 6, 4, 2, 99})
+	if len(polygon) != 4 {
+		t.Fatalf("FromCoordinates = %v, want 4 corners with the odd value left out", polygon)
+	}
+
+	tests := []struct {
+		name string
+		got  interface{}
+		want interface{}
+	}{
+		{"bounds", polygon.Bounds(), geometry.Rect{X: 4, Y: 2, W: 2, H: 4}},
+		{"bounds of nothing", geometry.Polygon{}.Bounds(), geometry.Rect{}},
+		{"area", polygon.Area(), 8.0},
+		{"scale", polygon.Scale(0.5, 1)[0], geometry.Point{X: 3, Y: 2}},
+		{"clip", polygon.Clip(5, 5), geometry.Polygon{{X: 5, Y: 2}, {X: 5, Y: 5}, {X: 4, Y: 5}, {X: 4, Y: 2}}},
+		{"relative", polygon.Relative(8, 8)[1], geometry.Point{X: 0.75, Y: 0.75}},
+		{"rect", geometry.Rect{X: 1, Y: 1, W: 2, H: 3}.Polygon(), geometry.Polygon{{X: 1, Y: 1}, {X: 3, Y: 1}, {X: 3, Y: 4}, {X: 1, Y: 4}}},
+		{"string", polygon[:2].String(), "(6, 2), (6, 6)"},
+	}
+	for _, test := range tests {
+		if !reflect.DeepEqual(test.got, test.want) {
+			t.Errorf("%v = %v, want %v", test.name, test.got, test.want)
+		}
+	}
+}
+
+func TestScaleBack(t *testing.T) {
+	// A rectangle found in an image downscaled to 0.3 of its size maps back
+	// to the original within a pixel.
+	original := geometry.Rect{X: 100, Y: 200, W: 300, H: 400}
+	found := original.Scale(0.3, 0.3)
+	back := found.Scale(1/0.3, 1/0.3)
+	for _, d := range []float64{back.X - original.X, back.Y - original.Y, back.W - original.W, back.H - original.H} {
+		if math.Abs(d) > 1e-9 {
+			t.Errorf("scaled back to %v, want %v", back, original)
+			break
+		}
+	}
+	if back.Image() != original.Image() {
+		t.Errorf("Image = %v, want %v", back.Image(), original.Image())
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Rectangle geometry.Rect    `json:"rectangle"`
+		Polygon   geometry.Polygon `json:"polygon"`
+	}{geometry.Rect{X: 1, Y: 2, W: 3, H: 4}, geometry.Polygon{{X: 1, Y: 2}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"rectangle":{"x":1,"y":2,"w":3,"h":4},"polygon":[{"x":1,"y":2}]}`
+	if string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,118 @@
+package visionkit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	goimage "image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+)
+
+// scaling is a Preprocessor that claims to scale every image by its value.
+type scaling float64
+
+func (s scaling) Preprocess(data []byte) ([]byte, float64, error) { return data, float64(s), nil }
+
+func TestLocationsScaledBack(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	client.Preprocessor = scaling(0.3)
+	image := visionkit.Bytes("label.png", []byte("not really a png"))
+
+	// The canned line spans 12,20 to 480,70 in the uploaded image, which
+	// is 40,67 to 1600,233 in the original once rounded to whole pixels.
+	want := geometry.Rect{X: 40, Y: 67, W: 1560, H: 166}
+
+	readResult, err := client.ReadText(context.Background(), image, computervision.Printed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := readResult.Pages[0].Lines[0]
+	if line.Rectangle != want || line.Polygon.Bounds() != want {
+		t.Errorf("read line = %v %v, want %v", line.Rectangle, line.Polygon, want)
+	}
+	if page := readResult.Pages[0]; page.Width != 3333 || page.Height != 1333 {
+		t.Errorf("page = %vx%v, want the size of the original", page.Width, page.Height)
+	}
+
+	ocrResult, err := client.OCR(context.Background(), image, computervision.En)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ocrResult.Lines()[0].Rectangle; got != want {
+		t.Errorf("ocr line = %v, want %v", got, want)
+	}
+}
+
+func TestOCRImageSize(t *testing.T) {
+	var data bytes.Buffer
+	if err := png.Encode(&data, goimage.NewGray(goimage.Rect(0, 0, 300, 200))); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name                  string
+		image                 visionkit.ImageSource
+		preprocessor          visionkit.Preprocessor
+		wantWidth, wantHeight int
+	}{
+		{name: "local", image: visionkit.Bytes("label.png", data.Bytes()), wantWidth: 300, wantHeight: 200},
+		{name: "scaled back", image: visionkit.Bytes("label.png", data.Bytes()), preprocessor: scaling(0.5), wantWidth: 600, wantHeight: 400},
+		{name: "remote", image: image},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := cvtest.NewServer()
+			defer server.Close()
+			client := server.Client()
+			client.Preprocessor = test.preprocessor
+
+			result, err := client.OCR(context.Background(), test.image, computervision.En)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Width != test.wantWidth || result.Height != test.wantHeight {
+				t.Errorf("size = %vx%v, want %vx%v", result.Width, result.Height, test.wantWidth, test.wantHeight)
+			}
+		})
+	}
+}
+
+func TestLocationsJSON(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	readResult, err := client.ReadText(context.Background(), image, computervision.Printed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	faces, err := client.DetectFaces(context.Background(), image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		result interface{}
+		want   string
+	}{
+		{"read line", readResult.Pages[0].Lines[0], `"rectangle":{"x":12,"y":20,"w":468,"h":50},"polygon":[{"x":12,"y":20},{"x":480,"y":22}`},
+		{"face", faces[0], `"rectangle":{"x":597,"y":162,"w":248,"h":248}`},
+	}
+	for _, test := range tests {
+		data, err := json.Marshal(test.result)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), test.want) {
+			t.Errorf("%v JSON = %s, want it to hold %s", test.name, data, test.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package visionkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+)
+
+// ErrUnknownDomain is returned by FindModel for a model the endpoint does
+// not list.
+var ErrUnknownDomain = errors.New("visionkit: unknown domain model")
+
+// DomainModel is a domain-specific model offered by the endpoint, with the
+// categories of the 86-category taxonomy it adds details to.
+type DomainModel struct {
+	Name       string   `json:"name"`
+	Categories []string `json:"categories"`
+}
+
+// modelList holds the models listed for a client, so that the list is only
+// requested once. The lock is only held to read and fill in the fields;
+// fetch is the request in flight, which the other callers wait for.
+type modelList struct {
+	mu     sync.Mutex
+	models []DomainModel
+	fetch  *modelFetch
+}
+
+// modelFetch is a request for the list of models, shared by every caller of
+// ListModels until it completes.
+type modelFetch struct {
+	done   chan struct{}
+	models []DomainModel
+	err    error
+}
+
+// ListModels returns the domain-specific models offered by the endpoint. The
+// list is requested once per client, and is kept in the client's Cache, if
+// any, for later runs. Concurrent callers share one request; a caller whose
+// context ends stops waiting without cancelling the request for the others
+// unless it is the one that sent it, in which case another caller sends it
+// again.
+func (c *Client) ListModels(ctx context.Context) ([]DomainModel, error) {
+	if c.models == nil {
+		return c.fetchModels(ctx)
+	}
+	for {
+		c.models.mu.Lock()
+		if models := c.models.models; models != nil {
+			c.models.mu.Unlock()
+			return models, nil
+		}
+		fetch, sent := c.models.fetch, false
+		if fetch == nil {
+			fetch, sent = &modelFetch{done: make(chan struct{})}, true
+			c.models.fetch = fetch
+		}
+		c.models.mu.Unlock()
+
+		if sent {
+			fetch.models, fetch.err = c.fetchModels(ctx)
+			c.models.mu.Lock()
+			c.models.fetch = nil
+			if fetch.err == nil {
+				c.models.models = fetch.models
+			}
+			c.models.mu.Unlock()
+			close(fetch.done)
+			return fetch.models, fetch.err
+		}
+
+		select {
+		case <-fetch.done:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("visionkit: listing the domain models: %w", ctx.Err())
+		}
+		if fetch.err == nil {
+			return fetch.models, nil
+		}
+		// A request that failed only because its sender gave up is sent
+		// again for this caller.
+		if !errors.Is(fetch.err, context.Canceled) && !errors.Is(fetch.err, context.DeadlineExceeded) {
+			return nil, fetch.err
+		}
+	}
+}
+
+// fetchModels reads the list of models from the client's Cache, or requests
+// it and stores it in the Cache.
+func (c *Client) fetchModels(ctx context.Context) ([]DomainModel, error) {
+	key := c.cacheKey("models", c.BaseClient.Endpoint, nil)
+	var models []DomainModel
+	if c.Cache != nil {
+		if data, ok, err := c.Cache.Get(key); ok && err == nil && json.Unmarshal(data, &models) == nil {
+			return models, nil
+		}
+	}
+
+	var listModelsResult computervision.ListModelsResult
+	err := c.Retry.Do(ctx, func() (err error) {
+		listModelsResult, err = c.BaseClient.ListModels(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("visionkit: listing the domain models: %w", err)
+	}
+	models = toDomainModels(listModelsResult.ModelsProperty)
+
+	if c.Cache != nil {
+		if data, err := json.Marshal(models); err == nil {
+			c.Cache.Set(key, data)
+		}
+	}
+	return models, nil
+}
+
+// FindModel returns the model listed by the endpoint under name, matched
+// without regard to case. For any other name it returns an error that wraps
+// ErrUnknownDomain and lists the available models.
+func (c *Client) FindModel(ctx context.Context, name string) (DomainModel, error) {
+	models, err := c.ListModels(ctx)
+	if err != nil {
+		return DomainModel{}, err
+	}
+	names := make([]string, len(models))
+	for i, model := range models {
+		if strings.EqualFold(model.Name, name) {
+			return model, nil
+		}
+		names[i] = model.Name
+	}
+	return DomainModel{}, fmt.Errorf("%w %q (available: %v)", ErrUnknownDomain, name, strings.Join(names, ", "))
+}
+
+func toDomainModels(models *[]computervision.ModelDescription) []DomainModel {
+	result := []DomainModel{}
+	if models == nil {
+		return result
+	}
+	for _, model := range *models {
+		domainModel := DomainModel{Name: stringValue(model.Name), Categories: []string{}}
+		if model.Categories != nil {
+			domainModel.Categories = append(domainModel.Categories, *model.Categories...)
+		}
+		result = append(result, domainModel)
+	}
+	return result
+}
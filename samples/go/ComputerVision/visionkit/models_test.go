@@ -0,0 +1,147 @@
+package visionkit_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cache"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+)
+
+func TestListModels(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	store := cache.NewMemory(0, 0)
+	client := server.Client()
+	client.Cache = store
+
+	for i := 0; i < 2; i++ {
+		models, err := client.ListModels(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(models) != 2 || models[0].Name != visionkit.DomainCelebrities || len(models[1].Categories) == 0 {
+			t.Errorf("ListModels = %+v, want celebrities and landmarks with their categories", models)
+		}
+	}
+	if requests := server.RequestCount(cvtest.RouteModels); requests != 1 {
+		t.Errorf("requests = %v, want the list requested once", requests)
+	}
+
+	// A later run finds the list in the cache.
+	later := server.Client()
+	later.Cache = store
+	later.ListModels(context.Background())
+	if requests := server.RequestCount(cvtest.RouteModels); requests != 1 {
+		t.Errorf("requests = %v, want the list read from the cache", requests)
+	}
+}
+
+func TestListModelsConcurrent(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	server.Enqueue(cvtest.RouteModels, cvtest.Response{Body: cvtest.ModelsBody, Delay: 50 * time.Millisecond})
+	client := server.Client()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = client.ListModels(context.Background())
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("caller %v: %v", i, err)
+		}
+	}
+	if requests := server.RequestCount(cvtest.RouteModels); requests != 1 {
+		t.Errorf("requests = %v, want one shared by every caller", requests)
+	}
+}
+
+func TestListModelsSenderCancelled(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	server.Enqueue(cvtest.RouteModels, cvtest.Response{Body: cvtest.ModelsBody, Delay: time.Second})
+	client := server.Client()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	sent := make(chan error)
+	go func() {
+		_, err := client.ListModels(ctx)
+		sent <- err
+	}()
+	// Let the first caller send the request before the second one waits
+	// for it.
+	for server.RequestCount(cvtest.RouteModels) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	models, err := client.ListModels(context.Background())
+	if err != nil || len(models) != 2 {
+		t.Errorf("ListModels = %v, %v, want the models from a request sent again", models, err)
+	}
+	if err := <-sent; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("sender error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if requests := server.RequestCount(cvtest.RouteModels); requests != 2 {
+		t.Errorf("requests = %v, want 2", requests)
+	}
+}
+
+func TestListModelsWaiterCancelled(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	server.Enqueue(cvtest.RouteModels, cvtest.Response{Body: cvtest.ModelsBody, Delay: 100 * time.Millisecond})
+	client := server.Client()
+
+	sent := make(chan error)
+	go func() {
+		_, err := client.ListModels(context.Background())
+		sent <- err
+	}()
+	for server.RequestCount(cvtest.RouteModels) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := client.ListModels(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("waiter error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err := <-sent; err != nil {
+		t.Errorf("sender error = %v, want the request to go on", err)
+	}
+	if requests := server.RequestCount(cvtest.RouteModels); requests != 1 {
+		t.Errorf("requests = %v, want 1", requests)
+	}
+}
+
+func TestFindModel(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr error
+	}{
+		{name: "landmarks", want: "landmarks"},
+		{name: "Celebrities", want: "celebrities"},
+		{name: "plants", wantErr: visionkit.ErrUnknownDomain},
+	}
+	for _, test := range tests {
+		model, err := client.FindModel(context.Background(), test.name)
+		if !errors.Is(err, test.wantErr) || model.Name != test.want {
+			t.Errorf("FindModel(%q) = %q, %v, want %q, %v", test.name, model.Name, err, test.want, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package visionkit
+
+import (
+	"context"
+	"io"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+)
+
+// DetectObjects returns the objects found in an image.
+func (c *Client) DetectObjects(ctx context.Context, image ImageSource) ([]DetectedObject, error) {
+	var objects []DetectedObject
+	err := c.cached(ctx, "objects", image, nil, &objects, func(image ImageSource) error {
+		detectResult, err := c.detectObjects(ctx, image)
+		objects = toObjects(detectResult.Objects)
+		return err
+	})
+	if err != nil {
+		return nil, wrapError("objects", image, err)
+	}
+	return objects, nil
+}
+
+// detectObjects calls DetectObjects for remote images, or
+// DetectObjectsInStream for everything else.
+func (c *Client) detectObjects(ctx context.Context, image ImageSource) (detectResult computervision.DetectResult, err error) {
+	err = c.call(ctx, image, func(imageURL computervision.ImageURL) (err error) {
+		detectResult, err = c.BaseClient.DetectObjects(ctx, imageURL)
+		return err
+	}, func(localImage io.ReadCloser) (err error) {
+		detectResult, err = c.BaseClient.DetectObjectsInStream(ctx, localImage)
+		return err
+	})
+	return detectResult, err
+}
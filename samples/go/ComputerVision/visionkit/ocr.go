@@ -0,0 +1,61 @@
+package visionkit
+
+import (
+	"context"
+	goimage "image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	_ "golang.org/x/image/bmp"
+)
+
+// OCR extracts printed text from an image, detecting the text orientation.
+func (c *Client) OCR(ctx context.Context, image ImageSource, language computervision.OcrLanguages) (OCRResult, error) {
+	var result OCRResult
+	err := c.cached(ctx, "ocr", image, []string{strings.ToLower(string(language))}, &result, func(image ImageSource) error {
+		ocrResult, err := c.recognizePrintedText(ctx, image, language)
+		result = toOCRResult(ocrResult)
+		result.Width, result.Height = imageSize(image)
+		return err
+	})
+	if err != nil {
+		return OCRResult{}, wrapError("ocr", image, err)
+	}
+	return result, nil
+}
+
+// recognizePrintedText calls RecognizePrintedText for remote images, or
+// RecognizePrintedTextInStream for everything else.
+func (c *Client) recognizePrintedText(ctx context.Context, image ImageSource, language computervision.OcrLanguages) (ocrResult computervision.OcrResult, err error) {
+	err = c.call(ctx, image, func(imageURL computervision.ImageURL) (err error) {
+		ocrResult, err = c.BaseClient.RecognizePrintedText(ctx, true, imageURL, language)
+		return err
+	}, func(localImage io.ReadCloser) (err error) {
+		ocrResult, err = c.BaseClient.RecognizePrintedTextInStream(ctx, true, localImage, language)
+		return err
+	})
+	return ocrResult, err
+}
+
+// imageSize reads the size of an uploaded local image from its header. It
+// returns zeros for a remote image or one in a format the service does not
+// take.
+func imageSize(image ImageSource) (width, height int) {
+	if _, remote := image.RemoteURL(); remote {
+		return 0, 0
+	}
+	reader, err := image.Open()
+	if err != nil {
+		return 0, 0
+	}
+	defer reader.Close()
+	config, _, err := goimage.DecodeConfig(reader)
+	if err != nil {
+		return 0, 0
+	}
+	return config.Width, config.Height
+}
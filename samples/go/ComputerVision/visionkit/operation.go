@@ -0,0 +1,118 @@
+package visionkit
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidOperationLocation is returned when an Operation-Location header
+// does not point at a read operation.
+var ErrInvalidOperationLocation = errors.New("visionkit: invalid Operation-Location")
+
+// ReadOperation is a handle to a batch Read API operation that has been
+// submitted to the service. It can be saved with encoding/json (or any
+// encoding.TextMarshaler-aware encoder) and passed to Client.ResumeReadText
+// later, for example after a process restart. The serialized form is the
+// Location, followed by "#scale=" and the Scale when the image was scaled.
+type ReadOperation struct {
+	// Location is the Operation-Location URL returned by the service.
+	Location string
+	// ID is the operation ID taken from the last segment of Location.
+	ID string
+	// Image is the Name of the image source, kept for error messages. It is
+	// not part of the serialized form.
+	Image string
+	// Scale is the factor the image was scaled by before upload, set by
+	// StartReadText when the client has a Preprocessor, so that the result
+	// is mapped back to the original image. Zero means 1.
+	Scale float64
+}
+
+// ParseOperationLocation parses the Operation-Location header returned by
+// BatchReadFile, for example
+// "https://westus.api.cognitive.microsoft.com/vision/v2.0/read/operations/{id}".
+// Trailing slashes and query strings are ignored. The URL must be absolute
+// and its path must end in ".../operations/{id}".
+func ParseOperationLocation(location string) (ReadOperation, error) {
+	location = strings.TrimSpace(location)
+	if location == "" {
+		return ReadOperation{}, fmt.Errorf("%w: the header is missing or empty", ErrInvalidOperationLocation)
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return ReadOperation{}, fmt.Errorf("%w: %v", ErrInvalidOperationLocation, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ReadOperation{}, fmt.Errorf("%w: %s is not an absolute http(s) URL", ErrInvalidOperationLocation, location)
+	}
+
+	segments := strings.Split(strings.TrimRight(u.Path, "/"), "/")
+	if len(segments) < 2 || !strings.EqualFold(segments[len(segments)-2], "operations") {
+		return ReadOperation{}, fmt.Errorf("%w: %s does not end in /operations/{id}", ErrInvalidOperationLocation, location)
+	}
+	id := segments[len(segments)-1]
+	if !validOperationID(id) {
+		return ReadOperation{}, fmt.Errorf("%w: operation ID %q is not valid", ErrInvalidOperationLocation, id)
+	}
+
+	return ReadOperation{Location: location, ID: id}, nil
+}
+
+// validOperationID accepts the GUIDs used by the service today and any other
+// non-empty identifier made of URL-safe characters.
+func validOperationID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == '~':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the Location of the operation.
+func (op ReadOperation) String() string {
+	return op.Location
+}
+
+// scaleFragment separates the Scale from the Location in the serialized
+// form. The service never puts a fragment in an Operation-Location.
+const scaleFragment = "#scale="
+
+// MarshalText encodes the operation as its Location and, if the image was
+// scaled, its Scale.
+func (op ReadOperation) MarshalText() ([]byte, error) {
+	if op.Scale == 0 || op.Scale == 1 {
+		return []byte(op.Location), nil
+	}
+	return []byte(op.Location + scaleFragment + strconv.FormatFloat(op.Scale, 'g', -1, 64)), nil
+}
+
+// UnmarshalText parses an operation written by MarshalText.
+func (op *ReadOperation) UnmarshalText(text []byte) error {
+	location, scale := string(text), 0.0
+	if i := strings.LastIndex(location, scaleFragment); i >= 0 {
+		var err error
+		scale, err = strconv.ParseFloat(location[i+len(scaleFragment):], 64)
+		if err != nil || scale <= 0 {
+			return fmt.Errorf("%w: scale %q is not a positive number", ErrInvalidOperationLocation, location[i+len(scaleFragment):])
+		}
+		location = location[:i]
+	}
+	parsed, err := ParseOperationLocation(location)
+	if err != nil {
+		return err
+	}
+	parsed.Scale = scale
+	*op = parsed
+	return nil
+}
@@ -0,0 +1,136 @@
+package visionkit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+)
+
+func TestParseOperationLocation(t *testing.T) {
+	const id = "8a3b2f4e-1c2d-4e5f-9a8b-7c6d5e4f3a2b"
+	tests := []struct {
+		location string
+		wantID   string
+	}{
+		{"https://westus.api.cognitive.microsoft.com/vision/v2.0/read/operations/" + id, id},
+		{"  https://westus.api.cognitive.microsoft.com/vision/v2.0/read/operations/" + id + "/  ", id},
+		{"https://westus.api.cognitive.microsoft.com/vision/v2.0/read/operations/" + id + "?api-version=2", id},
+		{"http://localhost:8080/vision/v2.0/read/Operations/op_1.2~3", "op_1.2~3"},
+		{"", ""},
+		{"/vision/v2.0/read/operations/" + id, ""},
+		{"ftp://example.com/read/operations/" + id, ""},
+		{"https://westus.api.cognitive.microsoft.com/vision/v2.0/read/results/" + id, ""},
+		{"https://westus.api.cognitive.microsoft.com/vision/v2.0/read/operations/", ""},
+		{"https://westus.api.cognitive.microsoft.com/vision/v2.0/read/operations/a%20b", ""},
+		{"https://exa mple.com/read/operations/" + id, ""},
+	}
+	for _, test := range tests {
+		operation, err := visionkit.ParseOperationLocation(test.location)
+		if test.wantID == "" {
+			if !errors.Is(err, visionkit.ErrInvalidOperationLocation) {
+				t.Errorf("ParseOperationLocation(%q) error = %v, want %v", test.location, err, visionkit.ErrInvalidOperationLocation)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseOperationLocation(%q): %v", test.location, err)
+			continue
+		}
+		if operation.ID != test.wantID {
+			t.Errorf("ParseOperationLocation(%q).ID = %q, want %q", test.location, operation.ID, test.wantID)
+		}
+	}
+}
+
+func TestReadOperationText(t *testing.T) {
+	const location = "https://westus.api.cognitive.microsoft.com/vision/v2.0/read/operations/abc-123"
+	tests := []struct {
+		name      string
+		scale     float64
+		wantText  string
+		wantScale float64
+	}{
+		{name: "unscaled", wantText: location},
+		{name: "scale of one", scale: 1, wantText: location},
+		{name: "scaled", scale: 0.4375, wantText: location + "#scale=0.4375", wantScale: 0.4375},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			operation, err := visionkit.ParseOperationLocation(location)
+			if err != nil {
+				t.Fatal(err)
+			}
+			operation.Image = "label.jpg"
+			operation.Scale = test.scale
+
+			text, err := operation.MarshalText()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(text) != test.wantText {
+				t.Errorf("MarshalText = %q, want %q", text, test.wantText)
+			}
+
+			data, err := json.Marshal(map[string]visionkit.ReadOperation{"operation": operation})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded map[string]visionkit.ReadOperation
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatal(err)
+			}
+			got := decoded["operation"]
+			if got.Location != location || got.ID != "abc-123" || got.Scale != test.wantScale || got.Image != "" {
+				t.Errorf("round trip = %+v, want the location, ID, and scale %v without the image", got, test.wantScale)
+			}
+		})
+	}
+}
+
+func TestReadOperationUnmarshalTextErrors(t *testing.T) {
+	for _, text := range []string{
+		"not a url",
+		"https://example.com/read/operations/abc#scale=",
+		"https://example.com/read/operations/abc#scale=zero",
+		"https://example.com/read/operations/abc#scale=-1",
+		"https://example.com/read/results/abc#scale=0.5",
+	} {
+		var operation visionkit.ReadOperation
+		if err := operation.UnmarshalText([]byte(text)); !errors.Is(err, visionkit.ErrInvalidOperationLocation) {
+			t.Errorf("UnmarshalText(%q) error = %v, want %v", text, err, visionkit.ErrInvalidOperationLocation)
+		}
+	}
+}
+
+func TestResumeSavedReadOperation(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	started, err := client.StartReadText(context.Background(), visionkit.URL("https://example.com/label.jpg"), computervision.Printed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := json.Marshal(started)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A new client, as after a restart, resumes the saved handle.
+	var operation visionkit.ReadOperation
+	if err := json.Unmarshal(saved, &operation); err != nil {
+		t.Fatal(err)
+	}
+	result, err := server.Client().ResumeReadText(context.Background(), operation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := result.Lines(); len(lines) != 1 || lines[0].Text != "Nutrition Facts" {
+		t.Errorf("lines = %+v, want the canned line", lines)
+	}
+}
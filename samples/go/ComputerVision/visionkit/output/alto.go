@@ -0,0 +1,128 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+)
+
+// altoHeader opens an ALTO 4 document measured in pixels.
+const altoHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<alto xmlns="http://www.loc.gov/standards/alto/ns-v4#" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://www.loc.gov/standards/alto/ns-v4# http://www.loc.gov/alto/v4/alto-4-2.xsd">
+  <Description>
+    <MeasurementUnit>pixel</MeasurementUnit>`
+
+// altoWriter writes the pages of every record as one ALTO 4 document, with a
+// Page for each page. Each OCR region and the lines of each Read page make a
+// TextBlock. Lines and words carry their position and, in a Shape, their
+// corners, in whole pixels; words the Read API flags as unsure carry WC. The
+// name of the image is in the Description when every page comes from the
+// same one.
+type altoWriter struct {
+	w io.Writer
+	layoutWriter
+}
+
+func (a *altoWriter) Write(record Record) error {
+	return a.add(record)
+}
+
+func (a *altoWriter) Close() error {
+	w := bufio.NewWriter(a.w)
+	fmt.Fprintln(w, altoHeader)
+	if image, ok := a.image(); ok {
+		fmt.Fprintln(w, "    <sourceImageInformation>")
+		fmt.Fprintf(w, "      <fileName>%v</fileName>\n", escape(image))
+		fmt.Fprintln(w, "    </sourceImageInformation>")
+	}
+	fmt.Fprintln(w, "  </Description>")
+	fmt.Fprintln(w, "  <Layout>")
+
+	var blockID, lineID, wordID int
+	for p, page := range a.pages {
+		pageRect := geometry.Rect{W: page.width, H: page.height}
+		fmt.Fprintf(w, "    <Page ID=\"page_%v\" PHYSICAL_IMG_NR=\"%v\" WIDTH=\"%v\" HEIGHT=\"%v\">\n",
+			p+1, p+1, pixels(page.width), pixels(page.height))
+		fmt.Fprintf(w, "      <PrintSpace ID=\"space_%v\" %v>\n", p+1, altoPosition(pageRect))
+		for _, block := range page.blocks {
+			blockID++
+			fmt.Fprintf(w, "        <TextBlock ID=\"block_%v\" %v%v>\n", blockID, altoPosition(block.rect), altoLang(page.language))
+			for _, line := range block.lines {
+				lineID++
+				fmt.Fprintf(w, "          <TextLine ID=\"line_%v\" %v>\n", lineID, altoPosition(line.rect))
+				writeAltoShape(w, "            ", line.polygon)
+				for i, word := range line.words {
+					wordID++
+					if i > 0 {
+						fmt.Fprintln(w, "            <SP/>")
+					}
+					confidence := ""
+					if word.confidence >= 0 {
+						confidence = fmt.Sprintf(" WC=\"%v\"", strconv.FormatFloat(word.confidence, 'f', -1, 64))
+					}
+					fmt.Fprintf(w, "            <String ID=\"word_%v\" %v CONTENT=\"%v\"%v", wordID, altoPosition(word.rect), escape(word.text), confidence)
+					if len(word.polygon) == 0 {
+						fmt.Fprintln(w, "/>")
+						continue
+					}
+					fmt.Fprintln(w, ">")
+					writeAltoShape(w, "              ", word.polygon)
+					fmt.Fprintln(w, "            </String>")
+				}
+				fmt.Fprintln(w, "          </TextLine>")
+			}
+			fmt.Fprintln(w, "        </TextBlock>")
+		}
+		fmt.Fprintln(w, "      </PrintSpace>")
+		fmt.Fprintln(w, "    </Page>")
+	}
+
+	fmt.Fprintln(w, "  </Layout>")
+	fmt.Fprintln(w, "</alto>")
+	return w.Flush()
+}
+
+// image returns the name of the image every page comes from, if there is
+// just one.
+func (a *altoWriter) image() (string, bool) {
+	if len(a.pages) == 0 {
+		return "", false
+	}
+	for _, page := range a.pages {
+		if page.image != a.pages[0].image {
+			return "", false
+		}
+	}
+	return a.pages[0].image, true
+}
+
+// writeAltoShape writes the corners of a line or word as a Shape, which comes
+// first in its element.
+func writeAltoShape(w io.Writer, indent string, polygon geometry.Polygon) {
+	if len(polygon) == 0 {
+		return
+	}
+	points := make([]string, len(polygon))
+	for i, point := range polygon {
+		points[i] = fmt.Sprintf("%v,%v", pixels(point.X), pixels(point.Y))
+	}
+	fmt.Fprintf(w, "%v<Shape><Polygon POINTS=\"%v\"/></Shape>\n", indent, strings.Join(points, " "))
+}
+
+// altoPosition formats the position attributes of an element.
+func altoPosition(rect geometry.Rect) string {
+	box := rect.Image()
+	return fmt.Sprintf("HPOS=\"%v\" VPOS=\"%v\" WIDTH=\"%v\" HEIGHT=\"%v\"",
+		box.Min.X, box.Min.Y, box.Dx(), box.Dy())
+}
+
+func altoLang(language string) string {
+	if language == "" || language == "unk" {
+		return ""
+	}
+	return fmt.Sprintf(" LANG=\"%v\"", escape(language))
+}
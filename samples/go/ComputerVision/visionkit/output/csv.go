@@ -0,0 +1,185 @@
+package output
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+)
+
+// ErrCSVUnsupported is returned by the CSV Writer for results that have no
+// CSV form. Tags, faces, objects, and brands, alone or in an Analysis,
+// celebrities, and lines of text do.
+var ErrCSVUnsupported = errors.New("output: result has no CSV form")
+
+// CSVHeader is the header row of the CSV format. Each row is a tag, face,
+// object, brand, celebrity, or line of text; kind tells which. The rectangle
+// columns are empty for tags, hint is the hint of a tag or the parents of an
+// object, such as "mammal > animal", and the name of a face is its gender.
+var CSVHeader = []string{"schema_version", "task", "image", "kind", "name", "confidence", "hint", "x", "y", "w", "h"}
+
+// csvWriter writes the header before the first row. Records of failed tasks
+// are skipped, since they have no rows.
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Write(record Record) error {
+	if record.Error != "" {
+		return nil
+	}
+	rows, err := csvRows(record.Result)
+	if err != nil {
+		return fmt.Errorf("%w: task %v", err, record.Task)
+	}
+
+	if !c.headerWritten {
+		if err := c.w.Write(CSVHeader); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+	prefix := []string{record.SchemaVersion, record.Task, record.Image}
+	for _, row := range rows {
+		if err := c.w.Write(append(append([]string{}, prefix...), row...)); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	if !c.headerWritten {
+		if err := c.w.Write(CSVHeader); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// csvRows returns the rows of a result, without the record columns.
+func csvRows(result interface{}) ([][]string, error) {
+	switch r := result.(type) {
+	case []visionkit.Tag:
+		return tagRows(r), nil
+	case []visionkit.DetectedObject:
+		return objectRows(r), nil
+	case []visionkit.Brand:
+		return brandRows(r), nil
+	case []visionkit.Face:
+		return faceRows(r), nil
+	case []visionkit.Celebrity:
+		return celebrityRows(r), nil
+	case visionkit.OCRResult:
+		return ocrRows(r), nil
+	case visionkit.ReadResult:
+		return readRows(r), nil
+	case visionkit.Analysis:
+		return analysisRows(r), nil
+	case *visionkit.Analysis:
+		return analysisRows(*r), nil
+	}
+	return nil, ErrCSVUnsupported
+}
+
+func analysisRows(analysis visionkit.Analysis) [][]string {
+	var rows [][]string
+	rows = append(rows, tagRows(analysis.Tags)...)
+	rows = append(rows, faceRows(analysis.Faces)...)
+	rows = append(rows, objectRows(analysis.Objects)...)
+	rows = append(rows, brandRows(analysis.Brands)...)
+	return rows
+}
+
+func tagRows(tags []visionkit.Tag) [][]string {
+	var rows [][]string
+	for _, tag := range tags {
+		rows = append(rows, []string{"tag", tag.Name, formatConfidence(tag.Confidence), tag.Hint, "", "", "", ""})
+	}
+	return rows
+}
+
+func objectRows(objects []visionkit.DetectedObject) [][]string {
+	var rows [][]string
+	for _, object := range objects {
+		parents := strings.Join(object.Path()[1:], " > ")
+		rows = append(rows, append([]string{"object", object.Name, formatConfidence(object.Confidence), parents}, rectColumns(object.Rectangle)...))
+	}
+	return rows
+}
+
+func brandRows(brands []visionkit.Brand) [][]string {
+	var rows [][]string
+	for _, brand := range brands {
+		rows = append(rows, append([]string{"brand", brand.Name, formatConfidence(brand.Confidence), ""}, rectColumns(brand.Rectangle)...))
+	}
+	return rows
+}
+
+func faceRows(faces []visionkit.Face) [][]string {
+	var rows [][]string
+	for _, face := range faces {
+		rows = append(rows, append([]string{"face", face.Gender, "", ""}, rectColumns(face.Rectangle)...))
+	}
+	return rows
+}
+
+func celebrityRows(celebrities []visionkit.Celebrity) [][]string {
+	var rows [][]string
+	for _, celebrity := range celebrities {
+		rows = append(rows, append([]string{"celebrity", celebrity.Name, formatConfidence(celebrity.Confidence), ""}, rectColumns(celebrity.Rectangle)...))
+	}
+	return rows
+}
+
+func ocrRows(ocrResult visionkit.OCRResult) [][]string {
+	var rows [][]string
+	for _, line := range ocrResult.Lines() {
+		rows = append(rows, append([]string{"text", line.Text(), "", ""}, rectColumns(line.Rectangle)...))
+	}
+	return rows
+}
+
+// readRows returns a row per line, with the rectangle around it. The lines
+// of pages measured in inches are skipped, so every rectangle is in pixels.
+func readRows(readResult visionkit.ReadResult) [][]string {
+	var rows [][]string
+	for _, page := range readResult.Pages {
+		if page.Unit != "" && page.Unit != "pixel" {
+			continue
+		}
+		for _, line := range page.Lines {
+			rows = append(rows, append([]string{"text", line.Text, "", ""}, rectColumns(line.Rectangle)...))
+		}
+	}
+	return rows
+}
+
+func rectColumns(rect geometry.Rect) []string {
+	return []string{
+		formatCoordinate(rect.X),
+		formatCoordinate(rect.Y),
+		formatCoordinate(rect.W),
+		formatCoordinate(rect.H),
+	}
+}
+
+func formatConfidence(confidence float64) string {
+	return strconv.FormatFloat(confidence, 'f', -1, 64)
+}
+
+func formatCoordinate(coordinate float64) string {
+	return strconv.FormatFloat(coordinate, 'f', -1, 64)
+}
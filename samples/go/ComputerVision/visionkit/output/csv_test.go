@@ -0,0 +1,42 @@
+package output_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/output"
+)
+
+func TestCSVObjectParents(t *testing.T) {
+	objects := []visionkit.DetectedObject{
+		{Name: "dog", Confidence: 0.5, Rectangle: geometry.Rect{X: 1, Y: 2, W: 3, H: 4}, Parents: []visionkit.ObjectParent{{Name: "mammal"}, {Name: "animal"}}},
+		{Name: "person", Confidence: 0.25},
+	}
+	var buf bytes.Buffer
+	w, err := output.NewWriter(&buf, output.CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(output.NewRecord("objects", "dog.jpg", objects, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hints := []string{}
+	for _, row := range rows[1:] {
+		hints = append(hints, row[6])
+	}
+	if want := []string{"mammal > animal", ""}; !reflect.DeepEqual(hints, want) {
+		t.Errorf("hints = %q, want %q", hints, want)
+	}
+}
@@ -0,0 +1,101 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+)
+
+// hocrCapabilities are the hOCR elements and properties the writer uses.
+const hocrCapabilities = "ocr_page ocr_carea ocr_par ocr_line ocrx_word ocrp_lang ocrp_poly ocrp_wconf"
+
+// hocrWriter writes the pages of every record as one hOCR 1.2 document, an
+// XHTML page with an ocr_page element for each page. Each OCR region and the
+// lines of each Read page make an ocr_carea holding one ocr_par. Lines and
+// words carry their bbox and, in poly, their corners; words the Read API
+// flags as unsure carry x_wconf.
+type hocrWriter struct {
+	w io.Writer
+	layoutWriter
+}
+
+func (h *hocrWriter) Write(record Record) error {
+	return h.add(record)
+}
+
+func (h *hocrWriter) Close() error {
+	w := bufio.NewWriter(h.w)
+	fmt.Fprintln(w, `<?xml version="1.0" encoding="UTF-8"?>`)
+	fmt.Fprintln(w, `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">`)
+	fmt.Fprintln(w, `<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="en" lang="en">`)
+	fmt.Fprintln(w, `<head>`)
+	fmt.Fprintln(w, `<title></title>`)
+	fmt.Fprintln(w, `<meta http-equiv="Content-Type" content="text/html; charset=utf-8" />`)
+	fmt.Fprintln(w, `<meta name="ocr-system" content="Azure Computer Vision API 2.0" />`)
+	fmt.Fprintf(w, "<meta name=\"ocr-capabilities\" content=\"%v\" />\n", hocrCapabilities)
+	fmt.Fprintln(w, `</head>`)
+	fmt.Fprintln(w, `<body>`)
+
+	var lineID, wordID int
+	for p, page := range h.pages {
+		n := p + 1
+		fmt.Fprintf(w, "<div class=\"ocr_page\" id=\"page_%v\"%v title=\"image &quot;%v&quot;; %v; ppageno %v\">\n",
+			n, hocrLang(page.language), escape(page.image), hocrBox(geometry.Rect{W: page.width, H: page.height}), page.number-1)
+		for b, block := range page.blocks {
+			fmt.Fprintf(w, "<div class=\"ocr_carea\" id=\"block_%v_%v\" title=\"%v\">\n", n, b+1, hocrBox(block.rect))
+			fmt.Fprintf(w, "<p class=\"ocr_par\" id=\"par_%v_%v\" title=\"%v\">\n", n, b+1, hocrBox(block.rect))
+			for _, line := range block.lines {
+				lineID++
+				fmt.Fprintf(w, "<span class=\"ocr_line\" id=\"line_%v\" title=\"%v\">", lineID, hocrTitle(line.rect, line.polygon))
+				for i, word := range line.words {
+					wordID++
+					if i > 0 {
+						fmt.Fprint(w, " ")
+					}
+					title := hocrTitle(word.rect, word.polygon)
+					if word.confidence >= 0 {
+						title += fmt.Sprintf("; x_wconf %v", pixels(word.confidence*100))
+					}
+					fmt.Fprintf(w, "<span class=\"ocrx_word\" id=\"word_%v\" title=\"%v\">%v</span>", wordID, title, escape(word.text))
+				}
+				fmt.Fprintln(w, "</span>")
+			}
+			fmt.Fprintln(w, "</p>")
+			fmt.Fprintln(w, "</div>")
+		}
+		fmt.Fprintln(w, "</div>")
+	}
+
+	fmt.Fprintln(w, `</body>`)
+	fmt.Fprintln(w, `</html>`)
+	return w.Flush()
+}
+
+// hocrBox formats the bbox property: the left, top, right, and bottom sides.
+func hocrBox(rect geometry.Rect) string {
+	min, max := rect.Min(), rect.Max()
+	return fmt.Sprintf("bbox %v %v %v %v", pixels(min.X), pixels(min.Y), pixels(max.X), pixels(max.Y))
+}
+
+// hocrTitle formats the bbox property and, when there are corners, the poly
+// property: the corners as x y pairs.
+func hocrTitle(rect geometry.Rect, polygon geometry.Polygon) string {
+	if len(polygon) == 0 {
+		return hocrBox(rect)
+	}
+	points := make([]string, len(polygon))
+	for i, point := range polygon {
+		points[i] = fmt.Sprintf("%v %v", pixels(point.X), pixels(point.Y))
+	}
+	return hocrBox(rect) + "; poly " + strings.Join(points, " ")
+}
+
+func hocrLang(language string) string {
+	if language == "" || language == "unk" {
+		return ""
+	}
+	return fmt.Sprintf(" lang=\"%v\" xml:lang=\"%v\"", escape(language), escape(language))
+}
@@ -0,0 +1,161 @@
+package output
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+)
+
+// ErrLayoutUnsupported is returned by the hOCR and ALTO Writers for results
+// other than text. Only OCR and Read results have pages, lines, and words.
+var ErrLayoutUnsupported = errors.New("output: result has no hOCR or ALTO form")
+
+// LowConfidence is the confidence given to the words the Read API flags as
+// "Low", 50 in hOCR and 0.5 in ALTO. The service gives no number, and the
+// words it does not flag get no confidence at all.
+const LowConfidence = 0.5
+
+// dotsPerInch is the resolution at which the pages of a PDF, which the Read
+// API measures in inches, are converted to pixels.
+const dotsPerInch = 300
+
+// layoutPage is a page of text, the form the hOCR and ALTO writers share.
+// Every location is in pixels.
+type layoutPage struct {
+	image string
+	// number is the page within the image, from 1.
+	number   int
+	width    float64
+	height   float64
+	language string
+	blocks   []layoutBlock
+}
+
+// layoutBlock is a region of text. Read results have one per page.
+type layoutBlock struct {
+	rect  geometry.Rect
+	lines []layoutLine
+}
+
+type layoutLine struct {
+	rect    geometry.Rect
+	polygon geometry.Polygon
+	words   []layoutWord
+}
+
+type layoutWord struct {
+	text    string
+	rect    geometry.Rect
+	polygon geometry.Polygon
+	// confidence is from 0 to 1, or -1 when the service gave none.
+	confidence float64
+}
+
+// layoutPages returns the pages of an OCR or Read result of an image.
+func layoutPages(image string, result interface{}) ([]layoutPage, error) {
+	switch r := result.(type) {
+	case visionkit.OCRResult:
+		return []layoutPage{ocrPage(image, r)}, nil
+	case visionkit.ReadResult:
+		var pages []layoutPage
+		for _, page := range r.Pages {
+			pages = append(pages, readPage(image, page))
+		}
+		return pages, nil
+	}
+	return nil, ErrLayoutUnsupported
+}
+
+// ocrPage turns an OCR result into a page the size of the image. When the
+// size is unknown, as for a remote image, the page reaches as far as the
+// text.
+func ocrPage(image string, result visionkit.OCRResult) layoutPage {
+	page := layoutPage{image: image, number: 1, language: result.Language, width: float64(result.Width), height: float64(result.Height)}
+	for _, region := range result.Regions {
+		block := layoutBlock{rect: region.Rectangle}
+		for _, line := range region.Lines {
+			l := layoutLine{rect: line.Rectangle, polygon: line.Rectangle.Polygon()}
+			for _, word := range line.Words {
+				l.words = append(l.words, layoutWord{text: word.Text, rect: word.Rectangle, polygon: word.Rectangle.Polygon(), confidence: -1})
+			}
+			block.lines = append(block.lines, l)
+		}
+		page.blocks = append(page.blocks, block)
+		if result.Width == 0 || result.Height == 0 {
+			max := block.rect.Max()
+			page.width = math.Max(page.width, max.X)
+			page.height = math.Max(page.height, max.Y)
+		}
+	}
+	return page
+}
+
+// readPage turns a page of a Read result into a page, with its lines in one
+// block. The pages of a PDF are converted from inches to pixels.
+func readPage(image string, page visionkit.ReadPage) layoutPage {
+	factor := 1.0
+	if page.Unit == "inch" {
+		factor = dotsPerInch
+	}
+	p := layoutPage{image: image, number: page.Page, width: page.Width * factor, height: page.Height * factor}
+	if p.number == 0 {
+		p.number = 1
+	}
+
+	var block layoutBlock
+	for _, line := range page.Lines {
+		polygon := line.Polygon.Scale(factor, factor)
+		l := layoutLine{rect: polygon.Bounds(), polygon: polygon}
+		for _, word := range line.Words {
+			polygon := word.Polygon.Scale(factor, factor)
+			confidence := -1.0
+			if word.Confidence == "Low" {
+				confidence = LowConfidence
+			}
+			l.words = append(l.words, layoutWord{text: word.Text, rect: polygon.Bounds(), polygon: polygon, confidence: confidence})
+		}
+		block.lines = append(block.lines, l)
+		block.rect = block.rect.Union(l.rect)
+	}
+	if len(block.lines) > 0 {
+		p.blocks = append(p.blocks, block)
+	}
+	return p
+}
+
+// layoutWriter collects the pages of the records, for the formats that are
+// one document whatever the number of images. Records of failed tasks are
+// skipped, since they have no pages.
+type layoutWriter struct {
+	pages []layoutPage
+}
+
+func (l *layoutWriter) add(record Record) error {
+	if record.Error != "" {
+		return nil
+	}
+	pages, err := layoutPages(record.Image, record.Result)
+	if err != nil {
+		return fmt.Errorf("%w: task %v", err, record.Task)
+	}
+	l.pages = append(l.pages, pages...)
+	return nil
+}
+
+// escape escapes text for XML and XHTML, in content and in quoted
+// attributes.
+func escape(text string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}
+
+// pixels rounds a coordinate to the whole pixels hOCR and ALTO use.
+func pixels(value float64) int {
+	return int(math.Round(value))
+}
@@ -0,0 +1,215 @@
+package output_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/output"
+)
+
+// ocrRecord is the OCR result of a receipt with text that must be escaped.
+var ocrRecord = output.NewRecord("ocr", `receipt "1".jpg`, visionkit.OCRResult{
+	Language: "en",
+	Regions: []visionkit.OCRRegion{{
+		Rectangle: geometry.Rect{X: 10, Y: 10, W: 190, H: 20},
+		Lines: []visionkit.OCRLine{{
+			Rectangle: geometry.Rect{X: 10, Y: 10, W: 190, H: 20},
+			Words: []visionkit.OCRWord{
+				{Text: "Fish", Rectangle: geometry.Rect{X: 10, Y: 10, W: 50, H: 20}},
+				{Text: `&"Chips"<1>`, Rectangle: geometry.Rect{X: 70, Y: 10, W: 130, H: 20}},
+			},
+		}},
+	}},
+}, nil)
+
+// readRecord is the Read result of a page of a PDF, measured in inches.
+var readRecord = output.NewRecord("read", "scan.pdf", visionkit.ReadResult{Pages: []visionkit.ReadPage{{
+	Page: 2, Unit: "inch", Width: 8.5, Height: 11,
+	Lines: []visionkit.ReadLine{{
+		Text:    "Total 12",
+		Polygon: geometry.Polygon{{X: 1, Y: 1}, {X: 3, Y: 1}, {X: 3, Y: 1.5}, {X: 1, Y: 1.5}},
+		Words: []visionkit.ReadWord{
+			{Text: "Total", Polygon: geometry.Polygon{{X: 1, Y: 1}, {X: 2, Y: 1}, {X: 2, Y: 1.5}, {X: 1, Y: 1.5}}},
+			{Text: "12", Polygon: geometry.Polygon{{X: 2.5, Y: 1}, {X: 3, Y: 1}, {X: 3, Y: 1.5}, {X: 2.5, Y: 1.5}}, Confidence: "Low"},
+		},
+	}},
+}}}, nil)
+
+// write writes records in format and returns the document.
+func write(t *testing.T, format output.Format, records ...output.Record) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := output.NewWriter(&buf, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, record := range records {
+		if err := w.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+type hocrWord struct {
+	Title string `xml:"title,attr"`
+	Text  string `xml:",chardata"`
+}
+
+type hocrDocument struct {
+	Pages []struct {
+		Title string     `xml:"title,attr"`
+		Lang  string     `xml:"lang,attr"`
+		Words []hocrWord `xml:"div>p>span>span"`
+	} `xml:"body>div"`
+}
+
+func TestHOCR(t *testing.T) {
+	document := write(t, output.HOCR, ocrRecord, output.NewRecord("read", "missing.jpg", nil, errors.New("not found")), readRecord)
+	var hocr hocrDocument
+	if err := xml.Unmarshal([]byte(document), &hocr); err != nil {
+		t.Fatalf("hOCR is not well-formed: %v\n%v", err, document)
+	}
+	if len(hocr.Pages) != 2 {
+		t.Fatalf("pages = %v, want 2 with the failed record skipped", len(hocr.Pages))
+	}
+
+	ocr, read := hocr.Pages[0], hocr.Pages[1]
+	if want := `image "receipt "1".jpg"; bbox 0 0 200 30; ppageno 0`; ocr.Title != want || ocr.Lang != "en" {
+		t.Errorf("OCR page title = %q in %q, want %q in en", ocr.Title, ocr.Lang, want)
+	}
+	wantWords := []hocrWord{
+		{Title: "bbox 10 10 60 30; poly 10 10 60 10 60 30 10 30", Text: "Fish"},
+		{Title: "bbox 70 10 200 30; poly 70 10 200 10 200 30 70 30", Text: `&"Chips"<1>`},
+	}
+	if !reflect.DeepEqual(ocr.Words, wantWords) {
+		t.Errorf("OCR words = %+v, want %+v", ocr.Words, wantWords)
+	}
+
+	if want := `image "scan.pdf"; bbox 0 0 2550 3300; ppageno 1`; read.Title != want || read.Lang != "" {
+		t.Errorf("Read page title = %q in %q, want %q without a language", read.Title, read.Lang, want)
+	}
+	wantWords = []hocrWord{
+		{Title: "bbox 300 300 600 450; poly 300 300 600 300 600 450 300 450", Text: "Total"},
+		{Title: "bbox 750 300 900 450; poly 750 300 900 300 900 450 750 450; x_wconf 50", Text: "12"},
+	}
+	if !reflect.DeepEqual(read.Words, wantWords) {
+		t.Errorf("Read words = %+v, want %+v", read.Words, wantWords)
+	}
+}
+
+type altoString struct {
+	Content string `xml:"CONTENT,attr"`
+	HPos    int    `xml:"HPOS,attr"`
+	WC      string `xml:"WC,attr"`
+}
+
+type altoDocument struct {
+	FileName string `xml:"Description>sourceImageInformation>fileName"`
+	Pages    []struct {
+		Width  int `xml:"WIDTH,attr"`
+		Height int `xml:"HEIGHT,attr"`
+		Blocks []struct {
+			Lang    string       `xml:"LANG,attr"`
+			Strings []altoString `xml:"TextLine>String"`
+		} `xml:"PrintSpace>TextBlock"`
+	} `xml:"Layout>Page"`
+}
+
+func TestALTO(t *testing.T) {
+	document := write(t, output.ALTO, ocrRecord, readRecord)
+	var alto altoDocument
+	if err := xml.Unmarshal([]byte(document), &alto); err != nil {
+		t.Fatalf("ALTO is not well-formed: %v\n%v", err, document)
+	}
+	if alto.FileName != "" {
+		t.Errorf("fileName = %q, want none for pages of two images", alto.FileName)
+	}
+	if len(alto.Pages) != 2 || len(alto.Pages[0].Blocks) != 1 || len(alto.Pages[1].Blocks) != 1 {
+		t.Fatalf("ALTO = %+v, want 2 pages of one block", alto)
+	}
+
+	ocr, read := alto.Pages[0], alto.Pages[1]
+	if ocr.Width != 200 || ocr.Height != 30 || ocr.Blocks[0].Lang != "en" {
+		t.Errorf("OCR page is %vx%v in %q, want 200x30 in en", ocr.Width, ocr.Height, ocr.Blocks[0].Lang)
+	}
+	want := []altoString{{Content: "Fish", HPos: 10}, {Content: `&"Chips"<1>`, HPos: 70}}
+	if !reflect.DeepEqual(ocr.Blocks[0].Strings, want) {
+		t.Errorf("OCR strings = %+v, want %+v", ocr.Blocks[0].Strings, want)
+	}
+
+	if read.Width != 2550 || read.Height != 3300 {
+		t.Errorf("Read page is %vx%v, want 2550x3300", read.Width, read.Height)
+	}
+	want = []altoString{{Content: "Total", HPos: 300}, {Content: "12", HPos: 750, WC: "0.5"}}
+	if !reflect.DeepEqual(read.Blocks[0].Strings, want) {
+		t.Errorf("Read strings = %+v, want %+v", read.Blocks[0].Strings, want)
+	}
+
+	if err := xml.Unmarshal([]byte(write(t, output.ALTO, readRecord)), &alto); err != nil {
+		t.Fatal(err)
+	}
+	if alto.FileName != "scan.pdf" {
+		t.Errorf("fileName = %q, want the only image", alto.FileName)
+	}
+}
+
+func TestOCRPageSize(t *testing.T) {
+	sized := ocrRecord
+	result := sized.Result.(visionkit.OCRResult)
+	result.Width, result.Height = 640, 480
+	sized.Result = result
+
+	// Without the size of the image, the page reaches as far as the text.
+	tests := []struct {
+		name       string
+		record     output.Record
+		wantBox    string
+		wantWidth  int
+		wantHeight int
+	}{
+		{name: "image size", record: sized, wantBox: "bbox 0 0 640 480", wantWidth: 640, wantHeight: 480},
+		{name: "text extent", record: ocrRecord, wantBox: "bbox 0 0 200 30", wantWidth: 200, wantHeight: 30},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var hocr hocrDocument
+			if err := xml.Unmarshal([]byte(write(t, output.HOCR, test.record)), &hocr); err != nil {
+				t.Fatal(err)
+			}
+			if len(hocr.Pages) != 1 || !strings.Contains(hocr.Pages[0].Title, "; "+test.wantBox+";") {
+				t.Errorf("hOCR pages = %+v, want one with %v", hocr.Pages, test.wantBox)
+			}
+
+			var alto altoDocument
+			if err := xml.Unmarshal([]byte(write(t, output.ALTO, test.record)), &alto); err != nil {
+				t.Fatal(err)
+			}
+			if len(alto.Pages) != 1 || alto.Pages[0].Width != test.wantWidth || alto.Pages[0].Height != test.wantHeight {
+				t.Errorf("ALTO pages = %+v, want one of %vx%v", alto.Pages, test.wantWidth, test.wantHeight)
+			}
+		})
+	}
+}
+
+func TestLayoutUnsupported(t *testing.T) {
+	for _, format := range []output.Format{output.HOCR, output.ALTO} {
+		w, err := output.NewWriter(&strings.Builder{}, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = w.Write(output.NewRecord("tags", "dog.jpg", []visionkit.Tag{{Name: "dog"}}, nil))
+		if !errors.Is(err, output.ErrLayoutUnsupported) {
+			t.Errorf("%v error = %v, want %v", format, err, output.ErrLayoutUnsupported)
+		}
+	}
+}
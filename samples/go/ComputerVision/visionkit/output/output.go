@@ -0,0 +1,132 @@
+// Package output writes visionkit results in machine-readable formats: pretty
+// JSON, newline-delimited JSON for batch runs, CSV for tags, objects, and
+// brands, and the hOCR and ALTO formats of OCR software for recognized text.
+//
+// Every JSON value written is a Record, whose schemaVersion field names the
+// version of the schema. The schema is made of the Record fields and the JSON
+// field names of the visionkit result types. Adding a field keeps the
+// version; renaming, removing, or changing the meaning of one bumps it.
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SchemaVersion is the version of the output schema.
+const SchemaVersion = "2.0"
+
+// Format is an output format.
+type Format string
+
+// The output formats. Text is the human-readable output of the command-line
+// tool and is not written by this package.
+const (
+	Text   Format = "text"
+	JSON   Format = "json"
+	NDJSON Format = "ndjson"
+	CSV    Format = "csv"
+	HOCR   Format = "hocr"
+	ALTO   Format = "alto"
+)
+
+// Formats lists the supported formats.
+var Formats = []Format{Text, JSON, NDJSON, CSV, HOCR, ALTO}
+
+// ErrUnknownFormat is returned by ParseFormat for an unsupported format.
+var ErrUnknownFormat = errors.New("output: unknown format")
+
+// ParseFormat matches name case-insensitively against Formats. "" is Text.
+func ParseFormat(name string) (Format, error) {
+	if name == "" {
+		return Text, nil
+	}
+	for _, format := range Formats {
+		if strings.EqualFold(name, string(format)) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("%w %q (want text, json, ndjson, csv, hocr, or alto)", ErrUnknownFormat, name)
+}
+
+// Record is the result of one task on one image. Result holds a visionkit
+// result type, for example []visionkit.Tag or visionkit.Analysis. Error is
+// set instead of Result when the task failed.
+type Record struct {
+	SchemaVersion string      `json:"schemaVersion"`
+	Task          string      `json:"task"`
+	Image         string      `json:"image"`
+	Result        interface{} `json:"result,omitempty"`
+	Error         string      `json:"error,omitempty"`
+}
+
+// NewRecord builds the Record of a task. err, if not nil, replaces result.
+func NewRecord(task, image string, result interface{}, err error) Record {
+	record := Record{SchemaVersion: SchemaVersion, Task: task, Image: image}
+	if err != nil {
+		record.Error = err.Error()
+	} else {
+		record.Result = result
+	}
+	return record
+}
+
+// Writer writes records. Close must be called once all the records are
+// written; some formats only write their output then.
+type Writer interface {
+	Write(record Record) error
+	Close() error
+}
+
+// NewWriter returns a Writer for format. Text has no Writer.
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	switch format {
+	case JSON:
+		return &jsonWriter{w: w}, nil
+	case NDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w)}, nil
+	case CSV:
+		return newCSVWriter(w), nil
+	case HOCR:
+		return &hocrWriter{w: w}, nil
+	case ALTO:
+		return &altoWriter{w: w}, nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownFormat, format)
+}
+
+// jsonWriter collects the records and writes them as one indented JSON array
+// on Close, so the output is a single document whatever the number of images.
+type jsonWriter struct {
+	w       io.Writer
+	records []Record
+}
+
+func (j *jsonWriter) Write(record Record) error {
+	j.records = append(j.records, record)
+	return nil
+}
+
+func (j *jsonWriter) Close() error {
+	records := j.records
+	if records == nil {
+		records = []Record{}
+	}
+	encoder := json.NewEncoder(j.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(records)
+}
+
+// ndjsonWriter writes every record on its own line as soon as it arrives.
+type ndjsonWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(record Record) error {
+	return n.encoder.Encode(record)
+}
+
+func (n *ndjsonWriter) Close() error { return nil }
@@ -0,0 +1,133 @@
+package output_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/output"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    output.Format
+		wantErr error
+	}{
+		{"", output.Text, nil},
+		{"NDJSON", output.NDJSON, nil},
+		{"alto", output.ALTO, nil},
+		{"xml", "", output.ErrUnknownFormat},
+	}
+	for _, test := range tests {
+		got, err := output.ParseFormat(test.name)
+		if got != test.want || !errors.Is(err, test.wantErr) {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q, %v", test.name, got, err, test.want, test.wantErr)
+		}
+	}
+	if _, err := output.NewWriter(&bytes.Buffer{}, output.Text); !errors.Is(err, output.ErrUnknownFormat) {
+		t.Errorf("NewWriter(text) error = %v, want %v", err, output.ErrUnknownFormat)
+	}
+}
+
+// tags and failed are a record of each kind.
+var (
+	tags   = []visionkit.Tag{{Name: "dog", Confidence: 0.99}, {Name: "grass", Confidence: 0.5, Hint: "plant"}}
+	failed = output.NewRecord("tags", "missing.jpg", tags, errors.New("image not found"))
+)
+
+// decoded is a Record read back, with its result left to decode.
+type decoded struct {
+	SchemaVersion string          `json:"schemaVersion"`
+	Task          string          `json:"task"`
+	Image         string          `json:"image"`
+	Result        json.RawMessage `json:"result"`
+	Error         string          `json:"error"`
+}
+
+// checkRecords checks records read back from JSON against the tags record
+// and the failed one.
+func checkRecords(t *testing.T, records []decoded) {
+	t.Helper()
+	if len(records) != 2 {
+		t.Fatalf("records = %+v, want 2", records)
+	}
+	for _, record := range records {
+		if record.SchemaVersion != output.SchemaVersion || record.Task != "tags" {
+			t.Errorf("record = %+v, want a tags record of schema %v", record, output.SchemaVersion)
+		}
+	}
+
+	var got []visionkit.Tag
+	if err := json.Unmarshal(records[0].Result, &got); err != nil {
+		t.Fatal(err)
+	}
+	if records[0].Image != "dog.jpg" || records[0].Error != "" || !reflect.DeepEqual(got, tags) {
+		t.Errorf("record = %+v with tags %+v, want the tags of dog.jpg", records[0], got)
+	}
+	if records[1].Image != "missing.jpg" || records[1].Error != "image not found" || records[1].Result != nil {
+		t.Errorf("record = %+v, want the error of missing.jpg without a result", records[1])
+	}
+}
+
+func TestJSON(t *testing.T) {
+	document := write(t, output.JSON, output.NewRecord("tags", "dog.jpg", tags, nil), failed)
+	var records []decoded
+	if err := json.Unmarshal([]byte(document), &records); err != nil {
+		t.Fatalf("JSON is not an array of records: %v\n%v", err, document)
+	}
+	checkRecords(t, records)
+
+	if empty := write(t, output.JSON); strings.TrimSpace(empty) != "[]" {
+		t.Errorf("JSON of no records = %q, want an empty array", empty)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	document := write(t, output.NDJSON, output.NewRecord("tags", "dog.jpg", tags, nil), failed)
+	var records []decoded
+	scanner := bufio.NewScanner(strings.NewReader(document))
+	for scanner.Scan() {
+		var record decoded
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %q is not a record: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	checkRecords(t, records)
+}
+
+func TestCSV(t *testing.T) {
+	document := write(t, output.CSV, output.NewRecord("tags", "dog.jpg", tags, nil), failed)
+	rows, err := csv.NewReader(strings.NewReader(document)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		output.CSVHeader,
+		{output.SchemaVersion, "tags", "dog.jpg", "tag", "dog", "0.99", "", "", "", "", ""},
+		{output.SchemaVersion, "tags", "dog.jpg", "tag", "grass", "0.5", "plant", "", "", "", ""},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q without the failed record", rows, want)
+	}
+
+	rows, err = csv.NewReader(strings.NewReader(write(t, output.CSV))).ReadAll()
+	if err != nil || !reflect.DeepEqual(rows, [][]string{output.CSVHeader}) {
+		t.Errorf("CSV of no records = %q, %v, want the header", rows, err)
+	}
+
+	w, err := output.NewWriter(&bytes.Buffer{}, output.CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(output.NewRecord("describe", "dog.jpg", visionkit.Description{}, nil)); !errors.Is(err, output.ErrCSVUnsupported) {
+		t.Errorf("error = %v, want %v", err, output.ErrCSVUnsupported)
+	}
+}
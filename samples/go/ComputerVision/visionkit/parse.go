@@ -0,0 +1,69 @@
+package visionkit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+)
+
+// ParseFeatures parses a comma-separated list of visual features, such as
+// "description,tags,objects". Names are matched without regard to case. An
+// empty list returns nil.
+func ParseFeatures(list string) ([]computervision.VisualFeatureTypes, error) {
+	var features []computervision.VisualFeatureTypes
+	for _, name := range splitList(list) {
+		found := false
+		for _, feature := range computervision.PossibleVisualFeatureTypesValues() {
+			if strings.EqualFold(string(feature), name) {
+				features = append(features, feature)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("visionkit: unknown visual feature %q", name)
+		}
+	}
+	return features, nil
+}
+
+// ParseDetails parses a comma-separated list of domain-specific details,
+// such as "celebrities,landmarks". Names are matched without regard to case.
+func ParseDetails(list string) ([]computervision.Details, error) {
+	var details []computervision.Details
+	for _, name := range splitList(list) {
+		found := false
+		for _, detail := range computervision.PossibleDetailsValues() {
+			if strings.EqualFold(string(detail), name) {
+				details = append(details, detail)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("visionkit: unknown detail %q", name)
+		}
+	}
+	return details, nil
+}
+
+// ParseTextRecognitionMode parses "printed" or "handwritten".
+func ParseTextRecognitionMode(name string) (computervision.TextRecognitionMode, error) {
+	for _, mode := range computervision.PossibleTextRecognitionModeValues() {
+		if strings.EqualFold(string(mode), name) {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("visionkit: unknown text recognition mode %q", name)
+}
+
+func splitList(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,384 @@
+package visionkit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+)
+
+// Errors returned by NewPlan and PlanResult.View.
+var (
+	ErrUnknownCapability = errors.New("visionkit: unknown capability")
+	ErrNotPlanned        = errors.New("visionkit: capability was not in the plan")
+	ErrNoResult          = errors.New("visionkit: capability has no result")
+)
+
+// Capability is one thing the client can find out about an image. Most
+// capabilities are visual features of a single AnalyzeImage call; objects,
+// the domain models, Read, and OCR each need a call of their own.
+type Capability string
+
+// The capabilities accepted by NewPlan. The names of the visual features are
+// the lowercase names of the matching computervision.VisualFeatureTypes.
+const (
+	CapabilityDescription Capability = "description"
+	CapabilityCategories  Capability = "categories"
+	CapabilityTags        Capability = "tags"
+	CapabilityFaces       Capability = "faces"
+	CapabilityAdult       Capability = "adult"
+	CapabilityColor       Capability = "color"
+	CapabilityImageType   Capability = "imagetype"
+	CapabilityBrands      Capability = "brands"
+	CapabilityObjects     Capability = "objects"
+	CapabilityCelebrities Capability = "celebrities"
+	CapabilityLandmarks   Capability = "landmarks"
+	CapabilityRead        Capability = "read"
+	CapabilityOCR         Capability = "ocr"
+)
+
+// Capabilities lists every capability in the order a PlanResult reports them.
+var Capabilities = []Capability{
+	CapabilityDescription,
+	CapabilityCategories,
+	CapabilityTags,
+	CapabilityFaces,
+	CapabilityAdult,
+	CapabilityColor,
+	CapabilityImageType,
+	CapabilityBrands,
+	CapabilityObjects,
+	CapabilityCelebrities,
+	CapabilityLandmarks,
+	CapabilityRead,
+	CapabilityOCR,
+}
+
+// analyzeCapabilities maps the capabilities merged into AnalyzeImage to their
+// visual features.
+var analyzeCapabilities = map[Capability]computervision.VisualFeatureTypes{
+	CapabilityDescription: computervision.VisualFeatureTypesDescription,
+	CapabilityCategories:  computervision.VisualFeatureTypesCategories,
+	CapabilityTags:        computervision.VisualFeatureTypesTags,
+	CapabilityFaces:       computervision.VisualFeatureTypesFaces,
+	CapabilityAdult:       computervision.VisualFeatureTypesAdult,
+	CapabilityColor:       computervision.VisualFeatureTypesColor,
+	CapabilityImageType:   computervision.VisualFeatureTypesImageType,
+	CapabilityBrands:      computervision.VisualFeatureTypesBrands,
+}
+
+// Plan is the set of calls that finds the requested capabilities of an image.
+// The visual features are merged into one AnalyzeImage call with the client's
+// Details and Language, and the other capabilities add one call each. Make a
+// Plan with NewPlan and run it with Client.RunPlan.
+type Plan struct {
+	// Capabilities are the requested capabilities, without duplicates, in
+	// the order of the Capabilities list.
+	Capabilities []Capability
+	// Features are the visual features of the merged AnalyzeImage call, if
+	// any.
+	Features []computervision.VisualFeatureTypes
+	// ReadMode is the text recognition mode of the Read capability. Defaults
+	// to computervision.Printed.
+	ReadMode computervision.TextRecognitionMode
+	// OCRLanguage is the language of the OCR capability. Defaults to
+	// computervision.En.
+	OCRLanguage computervision.OcrLanguages
+}
+
+// NewPlan returns the plan for the given capabilities.
+func NewPlan(capabilities ...Capability) (Plan, error) {
+	requested := make(map[Capability]bool)
+	for _, capability := range capabilities {
+		if !knownCapability(capability) {
+			return Plan{}, fmt.Errorf("%w %q", ErrUnknownCapability, capability)
+		}
+		requested[capability] = true
+	}
+
+	var plan Plan
+	for _, capability := range Capabilities {
+		if !requested[capability] {
+			continue
+		}
+		plan.Capabilities = append(plan.Capabilities, capability)
+		if feature, ok := analyzeCapabilities[capability]; ok {
+			plan.Features = append(plan.Features, feature)
+		}
+	}
+	return plan, nil
+}
+
+// Has reports whether the plan finds the capability.
+func (p Plan) Has(capability Capability) bool {
+	for _, planned := range p.Capabilities {
+		if planned == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// Calls returns the number of requests the plan sends, not counting retries
+// and the polls of a read operation. Without merging, every capability would
+// take one request.
+func (p Plan) Calls() int {
+	calls := len(p.Capabilities) - len(p.Features)
+	if len(p.Features) > 0 {
+		calls++
+	}
+	return calls
+}
+
+// PlanResult holds the results of a plan. Use View to get the result of one
+// capability in the form returned by the matching single-task method.
+type PlanResult struct {
+	Analysis    Analysis
+	Celebrities []Celebrity
+	Landmarks   []Landmark
+	Read        *ReadResult
+	OCR         *OCRResult
+
+	plan   Plan
+	errors map[Capability]error
+}
+
+// Err returns the error of the call that was to find the capability, or nil.
+func (r PlanResult) Err(capability Capability) error {
+	return r.errors[capability]
+}
+
+// View returns the result of one capability with the same type as the
+// single-task method: Description for CapabilityDescription, []Tag for
+// CapabilityTags, ReadResult for CapabilityRead, and so on. It returns the
+// error of the call that was to find it, ErrNotPlanned, or ErrNoResult when
+// the call left the result out, for example a PlanResult that RunPlan did
+// not fill in.
+func (r PlanResult) View(capability Capability) (interface{}, error) {
+	if !r.plan.Has(capability) {
+		return nil, fmt.Errorf("%w: %v", ErrNotPlanned, capability)
+	}
+	if err := r.errors[capability]; err != nil {
+		return nil, err
+	}
+	noResult := fmt.Errorf("%w: %v", ErrNoResult, capability)
+
+	switch capability {
+	case CapabilityDescription:
+		if r.Analysis.Description == nil {
+			return nil, noResult
+		}
+		return *r.Analysis.Description, nil
+	case CapabilityCategories:
+		return r.Analysis.Categories, nil
+	case CapabilityTags:
+		return r.Analysis.Tags, nil
+	case CapabilityFaces:
+		return r.Analysis.Faces, nil
+	case CapabilityAdult:
+		if r.Analysis.Adult == nil {
+			return nil, noResult
+		}
+		return *r.Analysis.Adult, nil
+	case CapabilityColor:
+		if r.Analysis.Color == nil {
+			return nil, noResult
+		}
+		return *r.Analysis.Color, nil
+	case CapabilityImageType:
+		if r.Analysis.ImageTypes == nil {
+			return nil, noResult
+		}
+		return *r.Analysis.ImageTypes, nil
+	case CapabilityBrands:
+		return r.Analysis.Brands, nil
+	case CapabilityObjects:
+		return r.Analysis.Objects, nil
+	case CapabilityCelebrities:
+		return r.Celebrities, nil
+	case CapabilityLandmarks:
+		return r.Landmarks, nil
+	case CapabilityRead:
+		if r.Read == nil {
+			return nil, noResult
+		}
+		return *r.Read, nil
+	default:
+		if r.OCR == nil {
+			return nil, noResult
+		}
+		return *r.OCR, nil
+	}
+}
+
+// RunPlan sends the calls of a plan for one image at the same time. A local
+// image is read once, and the bytes are shared by every call, so a source
+// made with Reader or Stdin can be used for several capabilities. The image
+// is prepared by the client's Preprocessor at most once, by the first call
+// whose result is not cached.
+//
+// A failed call does not stop the others. RunPlan returns the results of the
+// calls that succeeded and the first error in the order of the Capabilities
+// list; PlanResult.Err tells which capabilities are missing.
+func (c *Client) RunPlan(ctx context.Context, image ImageSource, plan Plan) (PlanResult, error) {
+	result := PlanResult{plan: plan, errors: make(map[Capability]error)}
+	if len(plan.Capabilities) == 0 {
+		return result, nil
+	}
+	if _, remote := image.RemoteURL(); !remote && plan.Calls() > 1 {
+		buffered, err := readAll(image)
+		if err != nil {
+			return result.failed(wrapError("open", image, err))
+		}
+		image = pending(buffered)
+	}
+
+	var calls []planCall
+	var analysis Analysis
+	var objects []DetectedObject
+	if len(plan.Features) > 0 {
+		calls = append(calls, planCall{capabilities: plan.analyzeCapabilities(), run: func() (err error) {
+			analysis, err = c.analyzeOnce(ctx, image, plan.Features)
+			return wrapError("analyze", image, err)
+		}})
+	}
+	if plan.Has(CapabilityObjects) {
+		calls = append(calls, planCall{capabilities: []Capability{CapabilityObjects}, run: func() (err error) {
+			objects, err = c.DetectObjects(ctx, image)
+			return err
+		}})
+	}
+	if plan.Has(CapabilityCelebrities) {
+		calls = append(calls, planCall{capabilities: []Capability{CapabilityCelebrities}, run: func() (err error) {
+			result.Celebrities, err = c.DetectCelebrities(ctx, image)
+			return err
+		}})
+	}
+	if plan.Has(CapabilityLandmarks) {
+		calls = append(calls, planCall{capabilities: []Capability{CapabilityLandmarks}, run: func() (err error) {
+			result.Landmarks, err = c.DetectLandmarks(ctx, image)
+			return err
+		}})
+	}
+	if plan.Has(CapabilityRead) {
+		calls = append(calls, planCall{capabilities: []Capability{CapabilityRead}, run: func() error {
+			mode := plan.ReadMode
+			if mode == "" {
+				mode = computervision.Printed
+			}
+			readResult, err := c.ReadText(ctx, image, mode)
+			if err == nil {
+				result.Read = &readResult
+			}
+			return err
+		}})
+	}
+	if plan.Has(CapabilityOCR) {
+		calls = append(calls, planCall{capabilities: []Capability{CapabilityOCR}, run: func() error {
+			language := plan.OCRLanguage
+			if language == "" {
+				language = computervision.En
+			}
+			ocrResult, err := c.OCR(ctx, image, language)
+			if err == nil {
+				result.OCR = &ocrResult
+			}
+			return err
+		}})
+	}
+
+	errs := make([]error, len(calls))
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		go func(i int, call planCall) {
+			defer wg.Done()
+			errs[i] = call.run()
+		}(i, call)
+	}
+	wg.Wait()
+	result.Analysis = analysis
+	result.Analysis.Objects = objects
+
+	for i, call := range calls {
+		for _, capability := range call.capabilities {
+			if errs[i] != nil {
+				result.errors[capability] = errs[i]
+			}
+		}
+	}
+	for _, capability := range plan.Capabilities {
+		if err := result.errors[capability]; err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// failed records err as the error of every capability of the plan.
+func (r PlanResult) failed(err error) (PlanResult, error) {
+	for _, capability := range r.plan.Capabilities {
+		r.errors[capability] = err
+	}
+	return r, err
+}
+
+// planCall is one request of a plan and the capabilities it finds.
+type planCall struct {
+	capabilities []Capability
+	run          func() error
+}
+
+// analyzeCapabilities returns the capabilities of the plan merged into the
+// AnalyzeImage call.
+func (p Plan) analyzeCapabilities() []Capability {
+	var capabilities []Capability
+	for _, capability := range p.Capabilities {
+		if _, ok := analyzeCapabilities[capability]; ok {
+			capabilities = append(capabilities, capability)
+		}
+	}
+	return capabilities
+}
+
+// readAll reads a local image into memory so that it can be sent more than
+// once.
+func readAll(image ImageSource) (BytesSource, error) {
+	localImage, err := image.Open()
+	if err != nil {
+		return BytesSource{}, err
+	}
+	defer localImage.Close()
+
+	var data bytes.Buffer
+	if _, err := data.ReadFrom(localImage); err != nil {
+		return BytesSource{}, err
+	}
+	return Bytes(image.Name(), data.Bytes()), nil
+}
+
+// knownCapability reports whether capability is in the Capabilities list.
+func knownCapability(capability Capability) bool {
+	for _, known := range Capabilities {
+		if known == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// capabilityOf returns the capability of a visual feature.
+func capabilityOf(feature computervision.VisualFeatureTypes) (Capability, bool) {
+	for capability, analyzeFeature := range analyzeCapabilities {
+		if analyzeFeature == feature {
+			return capability, true
+		}
+	}
+	if feature == computervision.VisualFeatureTypesObjects {
+		return CapabilityObjects, true
+	}
+	return "", false
+}
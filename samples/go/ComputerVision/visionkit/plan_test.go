@@ -0,0 +1,168 @@
+package visionkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+)
+
+func TestNewPlan(t *testing.T) {
+	tests := []struct {
+		name             string
+		capabilities     []visionkit.Capability
+		wantCapabilities []visionkit.Capability
+		wantFeatures     []computervision.VisualFeatureTypes
+		wantCalls        int
+		wantErr          error
+	}{
+		{name: "empty"},
+		{
+			name:             "merged in list order",
+			capabilities:     []visionkit.Capability{"tags", "description", "tags", "faces"},
+			wantCapabilities: []visionkit.Capability{visionkit.CapabilityDescription, visionkit.CapabilityTags, visionkit.CapabilityFaces},
+			wantFeatures:     []computervision.VisualFeatureTypes{computervision.VisualFeatureTypesDescription, computervision.VisualFeatureTypesTags, computervision.VisualFeatureTypesFaces},
+			wantCalls:        1,
+		},
+		{
+			name:             "calls of their own",
+			capabilities:     []visionkit.Capability{"ocr", "read", "objects", "landmarks", "celebrities"},
+			wantCapabilities: []visionkit.Capability{visionkit.CapabilityObjects, visionkit.CapabilityCelebrities, visionkit.CapabilityLandmarks, visionkit.CapabilityRead, visionkit.CapabilityOCR},
+			wantCalls:        5,
+		},
+		{
+			name:             "both",
+			capabilities:     []visionkit.Capability{"read", "color", "adult"},
+			wantCapabilities: []visionkit.Capability{visionkit.CapabilityAdult, visionkit.CapabilityColor, visionkit.CapabilityRead},
+			wantFeatures:     []computervision.VisualFeatureTypes{computervision.VisualFeatureTypesAdult, computervision.VisualFeatureTypesColor},
+			wantCalls:        2,
+		},
+		{name: "unknown", capabilities: []visionkit.Capability{"tags", "colour"}, wantErr: visionkit.ErrUnknownCapability},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			plan, err := visionkit.NewPlan(test.capabilities...)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Errorf("error = %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(plan.Capabilities, test.wantCapabilities) || !reflect.DeepEqual(plan.Features, test.wantFeatures) {
+				t.Errorf("plan = %v %v, want %v %v", plan.Capabilities, plan.Features, test.wantCapabilities, test.wantFeatures)
+			}
+			if calls := plan.Calls(); calls != test.wantCalls {
+				t.Errorf("Calls = %v, want %v", calls, test.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRunPlan(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	plan, err := visionkit.NewPlan("tags", "description", "faces", "objects", "read")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A Reader can be opened once only, so every call must share its bytes.
+	data := []byte("not really a jpeg")
+	result, err := client.RunPlan(context.Background(), visionkit.Reader("dog.jpg", bytes.NewReader(data)), plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for route, want := range map[string]int{cvtest.RouteAnalyze: 1, cvtest.RouteDetect: 1, cvtest.RouteRead: 1, cvtest.RouteTag: 0, cvtest.RouteDescribe: 0} {
+		if got := server.RequestCount(route); got != want {
+			t.Errorf("%v requests = %v, want %v", route, got, want)
+		}
+	}
+	for _, request := range server.Requests() {
+		if request.Method == http.MethodPost && !bytes.Equal(request.Body, data) {
+			t.Errorf("%v request sent %q, want the image", request.Route, request.Body)
+		}
+		if request.Route == cvtest.RouteAnalyze {
+			features := strings.ToLower(strings.Join(request.Query["visualFeatures"], ","))
+			if features != "description,tags,faces" {
+				t.Errorf("visualFeatures = %q, want the three merged features", features)
+			}
+		}
+	}
+
+	for _, capability := range plan.Capabilities {
+		if _, err := result.View(capability); err != nil {
+			t.Errorf("View(%v): %v", capability, err)
+		}
+	}
+	if tags, _ := result.View(visionkit.CapabilityTags); len(tags.([]visionkit.Tag)) == 0 {
+		t.Error("View(tags) returned no tags")
+	}
+	if read, _ := result.View(visionkit.CapabilityRead); len(read.(visionkit.ReadResult).Lines()) == 0 {
+		t.Error("View(read) returned no lines")
+	}
+}
+
+func TestRunPlanPartialFailure(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	server.Enqueue(cvtest.RouteAnalyze, cvtest.ServerError(http.StatusInternalServerError))
+	client := server.Client()
+	plan, _ := visionkit.NewPlan("tags", "objects", "celebrities")
+
+	result, err := client.RunPlan(context.Background(), image, plan)
+	if statusCode(err) != http.StatusInternalServerError {
+		t.Errorf("error = %v, want the error of the analyze call", err)
+	}
+	if result.Err(visionkit.CapabilityTags) == nil {
+		t.Error("Err(tags) = nil, want the error of the analyze call")
+	}
+	for _, capability := range []visionkit.Capability{visionkit.CapabilityObjects, visionkit.CapabilityCelebrities} {
+		if err := result.Err(capability); err != nil {
+			t.Errorf("Err(%v) = %v, want the call to succeed", capability, err)
+		}
+		if _, err := result.View(capability); err != nil {
+			t.Errorf("View(%v): %v", capability, err)
+		}
+	}
+	if len(result.Analysis.Objects) == 0 || len(result.Celebrities) == 0 {
+		t.Errorf("objects %v celebrities %v, want the results of the calls that succeeded", result.Analysis.Objects, result.Celebrities)
+	}
+}
+
+func TestPlanResultView(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	server.Handle(cvtest.RouteAnalyze, cvtest.Response{Body: `{"requestId": "1"}`})
+	client := server.Client()
+	plan, _ := visionkit.NewPlan("description", "tags")
+
+	result, err := client.RunPlan(context.Background(), image, plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The service left both features out of its answer, which reads as
+	// empty results rather than missing ones.
+	if description, err := result.View(visionkit.CapabilityDescription); err != nil || len(description.(visionkit.Description).Captions) != 0 {
+		t.Errorf("View(description) = %v, %v, want an empty description", description, err)
+	}
+	if tags, err := result.View(visionkit.CapabilityTags); err != nil || len(tags.([]visionkit.Tag)) != 0 {
+		t.Errorf("View(tags) = %v, %v, want no tags", tags, err)
+	}
+	if _, err := result.View(visionkit.CapabilityRead); !errors.Is(err, visionkit.ErrNotPlanned) {
+		t.Errorf("View(read) error = %v, want %v", err, visionkit.ErrNotPlanned)
+	}
+	if _, err := (visionkit.PlanResult{}).View(visionkit.CapabilityTags); !errors.Is(err, visionkit.ErrNotPlanned) {
+		t.Errorf("View of a zero PlanResult error = %v, want %v", err, visionkit.ErrNotPlanned)
+	}
+}
@@ -0,0 +1,196 @@
+package visionkit
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+)
+
+// Errors reported by ReadPoller. Use errors.Is to tell them apart, since they
+// are wrapped in a *ReadOperationError and then in an *Error.
+var (
+	// ErrReadFailed means the service finished the operation with the
+	// computervision.Failed status.
+	ErrReadFailed = errors.New("the service reported the read operation as failed")
+	// ErrReadTimeout means the operation was still running when the poller's
+	// Timeout or the context deadline expired.
+	ErrReadTimeout = errors.New("timed out waiting for the read operation to complete")
+)
+
+// ReadOperationError is returned by ReadPoller when a read operation does not
+// succeed. Status is the last status reported by the service.
+type ReadOperationError struct {
+	OperationID string
+	Status      computervision.TextOperationStatusCodes
+	Err         error
+}
+
+func (e *ReadOperationError) Error() string {
+	return "read operation " + e.OperationID + " (" + string(e.Status) + "): " + e.Err.Error()
+}
+
+// Unwrap returns ErrReadFailed, ErrReadTimeout, or the context error.
+func (e *ReadOperationError) Unwrap() error { return e.Err }
+
+// Backoff is an exponential backoff with jitter. The zero value uses the
+// defaults listed on each field.
+type Backoff struct {
+	// Initial is the delay before the first retry. Defaults to 1 second.
+	Initial time.Duration
+	// Max caps the delay between two attempts. Defaults to 10 seconds.
+	Max time.Duration
+	// Multiplier grows the delay after each attempt. Defaults to 1.5.
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction, in both
+	// directions, so that many clients do not poll in lockstep. Defaults to
+	// 0.2; a negative value turns jitter off.
+	Jitter float64
+}
+
+func (b Backoff) withDefaults() Backoff {
+	if b.Initial <= 0 {
+		b.Initial = time.Second
+	}
+	if b.Max <= 0 {
+		b.Max = 10 * time.Second
+	}
+	if b.Multiplier < 1 {
+		b.Multiplier = 1.5
+	}
+	if b.Jitter == 0 {
+		b.Jitter = 0.2
+	}
+	return b
+}
+
+// Delay returns the delay before the given retry, counting from 0.
+func (b Backoff) Delay(retry int) time.Duration {
+	b = b.withDefaults()
+	delay := float64(b.Initial)
+	for i := 0; i < retry && delay < float64(b.Max); i++ {
+		delay *= b.Multiplier
+	}
+	if delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+	if b.Jitter > 0 {
+		delay *= 1 + b.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay)
+}
+
+// ReadPoller waits for a batch Read API operation to complete. The zero
+// value is ready to use.
+type ReadPoller struct {
+	// Backoff sets the delays between calls to GetReadOperationResult. A
+	// Retry-After header on the response takes precedence over it.
+	Backoff Backoff
+	// Timeout bounds the whole wait, in addition to any deadline on the
+	// context. Defaults to 2 minutes; a negative value leaves only the
+	// context deadline.
+	Timeout time.Duration
+	// OnStatus, if set, is called each time the operation is found to be
+	// still running, with the delay before the next attempt.
+	OnStatus func(operationID string, status computervision.TextOperationStatusCodes, wait time.Duration)
+	// Retry, if set, retries each call to GetReadOperationResult that fails,
+	// for example with a 429. Client.ResumeReadText uses the client's Retry
+	// policy when it is nil.
+	Retry *RetryPolicy
+}
+
+// Poll calls GetReadOperationResult until the operation succeeds, fails, or
+// the wait is cancelled. It returns the last result it received together with
+// a *ReadOperationError if the operation did not succeed.
+func (p ReadPoller) Poll(ctx context.Context, client computervision.BaseClient, operationID string) (computervision.ReadOperationResult, error) {
+	timeout := p.Timeout
+	if timeout == 0 {
+		timeout = 2 * time.Minute
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var readOperationResult computervision.ReadOperationResult
+	for retry := 0; ; retry++ {
+		err := p.retry(ctx, func() (err error) {
+			readOperationResult, err = client.GetReadOperationResult(ctx, operationID)
+			return err
+		})
+		if ctx.Err() != nil {
+			return readOperationResult, p.contextError(ctx, operationID, readOperationResult.Status)
+		}
+		if err != nil {
+			return readOperationResult, err
+		}
+
+		switch readOperationResult.Status {
+		case computervision.Succeeded:
+			return readOperationResult, nil
+		case computervision.Failed:
+			return readOperationResult, &ReadOperationError{OperationID: operationID, Status: readOperationResult.Status, Err: ErrReadFailed}
+		}
+
+		wait, ok := retryAfter(readOperationResult.Response.Response)
+		if !ok {
+			wait = p.Backoff.Delay(retry)
+		}
+		if p.OnStatus != nil {
+			p.OnStatus(operationID, readOperationResult.Status, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return readOperationResult, p.contextError(ctx, operationID, readOperationResult.Status)
+		case <-timer.C:
+		}
+	}
+}
+
+// retry runs call with the poller's Retry policy, or once without one.
+func (p ReadPoller) retry(ctx context.Context, call func() error) error {
+	if p.Retry == nil {
+		return call()
+	}
+	return p.Retry.Do(ctx, call)
+}
+
+// contextError turns an expired deadline into ErrReadTimeout and keeps any
+// other cancellation as the context error.
+func (p ReadPoller) contextError(ctx context.Context, operationID string, status computervision.TextOperationStatusCodes) error {
+	err := ctx.Err()
+	if err == context.DeadlineExceeded {
+		err = ErrReadTimeout
+	}
+	return &ReadOperationError{OperationID: operationID, Status: status, Err: err}
+}
+
+// retryAfter reads the Retry-After header of resp, which holds either a
+// number of seconds or an HTTP date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
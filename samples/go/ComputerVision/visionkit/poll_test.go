@@ -0,0 +1,159 @@
+package visionkit_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+)
+
+func TestReadPollerPoll(t *testing.T) {
+	tests := []struct {
+		name      string
+		polls     int
+		queued    []cvtest.Response
+		timeout   time.Duration
+		fails     bool
+		wantErr   error
+		wantPolls int
+	}{
+		{name: "succeeds at once", wantPolls: 1},
+		{name: "succeeds after running", polls: 3, wantPolls: 4},
+		{
+			name:      "failed",
+			queued:    []cvtest.Response{{Body: `{"status": "Failed"}`}},
+			fails:     true,
+			wantErr:   visionkit.ErrReadFailed,
+			wantPolls: 1,
+		},
+		{
+			name:      "times out",
+			polls:     1000,
+			timeout:   50 * time.Millisecond,
+			fails:     true,
+			wantErr:   visionkit.ErrReadTimeout,
+			wantPolls: -1,
+		},
+		{
+			name:      "not found",
+			queued:    []cvtest.Response{cvtest.ServerError(http.StatusNotFound)},
+			fails:     true,
+			wantPolls: 1,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := cvtest.NewServer()
+			defer server.Close()
+			server.ReadPolls = test.polls
+			server.Enqueue(cvtest.RouteReadOperation, test.queued...)
+			client := server.Client()
+			client.ReadPoller.Timeout = test.timeout
+
+			operation, err := client.StartReadText(context.Background(), visionkit.URL("https://example.com/label.jpg"), computervision.Printed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := client.ResumeReadText(context.Background(), operation)
+
+			switch {
+			case !test.fails && err != nil:
+				t.Fatalf("ResumeReadText: %v", err)
+			case !test.fails:
+				if lines := result.Lines(); len(lines) != 1 || lines[0].Text != "Nutrition Facts" {
+					t.Errorf("lines = %+v, want the canned line", lines)
+				}
+			case err == nil:
+				t.Fatal("ResumeReadText succeeded, want an error")
+			}
+			var operationError *visionkit.ReadOperationError
+			if test.wantErr != nil && (!errors.Is(err, test.wantErr) || !errors.As(err, &operationError)) {
+				t.Errorf("error = %v, want %v in a *ReadOperationError", err, test.wantErr)
+			}
+			if polls := server.RequestCount(cvtest.RouteReadOperation); test.wantPolls >= 0 && polls != test.wantPolls {
+				t.Errorf("polls = %v, want %v", polls, test.wantPolls)
+			}
+		})
+	}
+}
+
+func TestReadPollerRetryAfter(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	server.Enqueue(cvtest.RouteReadOperation, cvtest.Response{
+		Header: http.Header{"Retry-After": {"30"}},
+		Body:   `{"status": "Running"}`,
+	})
+	client := server.Client()
+
+	var waits []time.Duration
+	poller := client.ReadPoller
+	poller.Timeout = 50 * time.Millisecond
+	poller.OnStatus = func(operationID string, status computervision.TextOperationStatusCodes, wait time.Duration) {
+		if status != computervision.Running {
+			t.Errorf("status = %v, want Running", status)
+		}
+		waits = append(waits, wait)
+	}
+
+	operation, err := client.StartReadText(context.Background(), visionkit.URL("https://example.com/label.jpg"), computervision.Printed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = poller.Poll(context.Background(), client.BaseClient, operation.ID)
+	if !errors.Is(err, visionkit.ErrReadTimeout) {
+		t.Errorf("error = %v, want %v while waiting out the Retry-After", err, visionkit.ErrReadTimeout)
+	}
+	if len(waits) != 1 || waits[0] != 30*time.Second {
+		t.Errorf("waits = %v, want [30s] from the Retry-After header", waits)
+	}
+}
+
+func TestReadPollerCancel(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	server.ReadPolls = 1000
+	client := server.Client()
+
+	operation, err := client.StartReadText(context.Background(), visionkit.URL("https://example.com/label.jpg"), computervision.Printed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	client.ReadPoller.OnStatus = func(string, computervision.TextOperationStatusCodes, time.Duration) { cancel() }
+	_, err = client.ResumeReadText(ctx, operation)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	backoff := visionkit.Backoff{Initial: time.Second, Max: 5 * time.Second, Multiplier: 2, Jitter: -1}
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{3, 5 * time.Second},
+		{20, 5 * time.Second},
+	}
+	for _, test := range tests {
+		if got := backoff.Delay(test.retry); got != test.want {
+			t.Errorf("Delay(%v) = %v, want %v", test.retry, got, test.want)
+		}
+	}
+
+	jittered := visionkit.Backoff{Initial: time.Second, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		if got := jittered.Delay(0); got < 500*time.Millisecond || got > 1500*time.Millisecond {
+			t.Fatalf("Delay(0) with jitter 0.5 = %v, want within 0.5s to 1.5s", got)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package visionkit
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"sync"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+)
+
+// ErrPDFUnsupported is returned, with a Preprocessor, when a PDF document is
+// sent to any task but Read, the only one that takes documents.
+var ErrPDFUnsupported = errors.New("visionkit: only the Read API takes PDF documents")
+
+// Preprocessor prepares local images for upload, for example by downscaling
+// the ones over the size limits of the service. See the preprocess package.
+type Preprocessor interface {
+	// Preprocess returns the image to upload in place of data, and the
+	// factor its sides were scaled by, 1 if they were not.
+	Preprocess(data []byte) ([]byte, float64, error)
+}
+
+// preparedImage is a local image that went through the client's
+// Preprocessor. It keeps the identity of the original image for the cache
+// and the scale of the uploaded one to map the results back.
+type preparedImage struct {
+	BytesSource
+	identity string
+	scale    float64
+}
+
+// pendingImage is a local image shared by the calls of a plan. It is
+// prepared by the first call that misses the cache, and the others use the
+// same prepared image, so a plan whose results are all cached never runs the
+// Preprocessor.
+type pendingImage struct {
+	BytesSource
+	identity string
+
+	once     sync.Once
+	prepared ImageSource
+	err      error
+}
+
+// pending buffers a local image for the calls of a plan.
+func pending(buffered BytesSource) *pendingImage {
+	return &pendingImage{BytesSource: buffered, identity: digest(buffered.Data)}
+}
+
+// prepare reads a local image and runs it through the client's Preprocessor
+// for the task op. A PDF document is refused unless op is "read", before it
+// is uploaded. Remote images, images already prepared, and every image of a
+// client without a Preprocessor are returned as they are.
+func (c *Client) prepare(op string, image ImageSource) (ImageSource, error) {
+	if c.Preprocessor == nil {
+		return image, nil
+	}
+	if _, remote := image.RemoteURL(); remote {
+		return image, nil
+	}
+	switch i := image.(type) {
+	case preparedImage:
+		return image, checkDocument(op, i.Data)
+	case *pendingImage:
+		if err := checkDocument(op, i.Data); err != nil {
+			return nil, err
+		}
+		i.once.Do(func() {
+			i.prepared, i.err = c.preprocess(i.BytesSource, i.identity)
+		})
+		return i.prepared, i.err
+	}
+
+	buffered, ok := image.(BytesSource)
+	if !ok {
+		var err error
+		if buffered, err = readAll(image); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkDocument(op, buffered.Data); err != nil {
+		return nil, err
+	}
+	return c.preprocess(buffered, digest(buffered.Data))
+}
+
+// checkDocument refuses a PDF document for any task but Read.
+func checkDocument(op string, data []byte) error {
+	if op != "read" && bytes.HasPrefix(data, []byte("%PDF-")) {
+		return ErrPDFUnsupported
+	}
+	return nil
+}
+
+// preprocess runs the bytes of a local image through the client's
+// Preprocessor.
+func (c *Client) preprocess(buffered BytesSource, identity string) (ImageSource, error) {
+	data, scale, err := c.Preprocessor.Preprocess(buffered.Data)
+	if err != nil {
+		return nil, err
+	}
+	return preparedImage{
+		BytesSource: Bytes(buffered.Label, data),
+		identity:    identity,
+		scale:       scale,
+	}, nil
+}
+
+// scaleBack maps the locations of a result found in an image scaled by scale
+// back to the original image. v points to the result, as in Client.cached.
+func scaleBack(v interface{}, scale float64) {
+	if scale == 1 || scale <= 0 {
+		return
+	}
+	factor := 1 / scale
+
+	switch r := v.(type) {
+	case *Analysis:
+		for i := range r.Categories {
+			scaleCelebrities(r.Categories[i].Celebrities, factor)
+		}
+		scaleFaces(r.Faces, factor)
+		scaleObjects(r.Objects, factor)
+		scaleBrands(r.Brands, factor)
+	case *[]DetectedObject:
+		scaleObjects(*r, factor)
+	case *DomainResult:
+		for _, item := range r.Items {
+			if item.Rectangle != nil {
+				*item.Rectangle = scaleRect(*item.Rectangle, factor)
+			}
+		}
+		r.Metadata.Width = scaleInt(r.Metadata.Width, factor)
+		r.Metadata.Height = scaleInt(r.Metadata.Height, factor)
+	case *ReadResult:
+		for i := range r.Pages {
+			scaleReadPage(&r.Pages[i], factor)
+		}
+	case *OCRResult:
+		r.Width = scaleInt(r.Width, factor)
+		r.Height = scaleInt(r.Height, factor)
+		for i := range r.Regions {
+			region := &r.Regions[i]
+			region.Rectangle = scaleRect(region.Rectangle, factor)
+			for j := range region.Lines {
+				line := &region.Lines[j]
+				line.Rectangle = scaleRect(line.Rectangle, factor)
+				for k := range line.Words {
+					line.Words[k].Rectangle = scaleRect(line.Words[k].Rectangle, factor)
+				}
+			}
+		}
+	}
+}
+
+func scaleFaces(faces []Face, factor float64) {
+	for i := range faces {
+		faces[i].Rectangle = scaleRect(faces[i].Rectangle, factor)
+	}
+}
+
+func scaleCelebrities(celebrities []Celebrity, factor float64) {
+	for i := range celebrities {
+		celebrities[i].Rectangle = scaleRect(celebrities[i].Rectangle, factor)
+	}
+}
+
+func scaleObjects(objects []DetectedObject, factor float64) {
+	for i := range objects {
+		objects[i].Rectangle = scaleRect(objects[i].Rectangle, factor)
+	}
+}
+
+func scaleBrands(brands []Brand, factor float64) {
+	for i := range brands {
+		brands[i].Rectangle = scaleRect(brands[i].Rectangle, factor)
+	}
+}
+
+// scaleRect scales a rectangle, keeping it in whole pixels.
+func scaleRect(rect geometry.Rect, factor float64) geometry.Rect {
+	scaled := rect.Scale(factor, factor).Image()
+	return geometry.Rect{
+		X: float64(scaled.Min.X),
+		Y: float64(scaled.Min.Y),
+		W: float64(scaled.Dx()),
+		H: float64(scaled.Dy()),
+	}
+}
+
+// scaleReadPage scales the lines of a page measured in pixels. The pages of
+// a PDF are measured in inches and are never preprocessed.
+func scaleReadPage(page *ReadPage, factor float64) {
+	if page.Unit != "" && page.Unit != "pixel" {
+		return
+	}
+	page.Width = math.Round(page.Width * factor)
+	page.Height = math.Round(page.Height * factor)
+	for i := range page.Lines {
+		line := &page.Lines[i]
+		scaleReadLocation(&line.Rectangle, &line.Polygon, factor)
+		for j := range line.Words {
+			word := &line.Words[j]
+			scaleReadLocation(&word.Rectangle, &word.Polygon, factor)
+		}
+	}
+}
+
+// scaleReadLocation scales the corners of a line or word to whole pixels,
+// like the ones the service returns, and sets the rectangle around them.
+func scaleReadLocation(rect *geometry.Rect, polygon *geometry.Polygon, factor float64) {
+	scaled := polygon.Scale(factor, factor)
+	for i := range scaled {
+		scaled[i].X = math.Round(scaled[i].X)
+		scaled[i].Y = math.Round(scaled[i].Y)
+	}
+	*polygon = scaled
+	*rect = scaled.Bounds()
+}
+
+func scaleInt(value int, factor float64) int {
+	return int(math.Round(float64(value) * factor))
+}
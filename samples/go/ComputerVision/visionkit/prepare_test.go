@@ -0,0 +1,47 @@
+package visionkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/preprocess"
+)
+
+func TestPDFOnlyRead(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+	client.Preprocessor = preprocess.Preprocessor{}
+	pdf := []byte("%PDF-1.7\n%fake document\n")
+	document := visionkit.Bytes("scan.pdf", pdf)
+
+	if _, err := client.Tag(context.Background(), document); !errors.Is(err, visionkit.ErrPDFUnsupported) {
+		t.Errorf("Tag error = %v, want %v", err, visionkit.ErrPDFUnsupported)
+	}
+	if requests := len(server.Requests()); requests != 0 {
+		t.Errorf("requests = %v, want the PDF refused before upload", requests)
+	}
+
+	if _, err := client.ReadText(context.Background(), document, computervision.Printed); err != nil {
+		t.Fatalf("ReadText: %v", err)
+	}
+	requests := server.Requests()
+	if len(requests) == 0 || requests[0].Route != cvtest.RouteRead || !bytes.Equal(requests[0].Body, pdf) {
+		t.Errorf("requests = %+v, want the PDF uploaded unchanged to Read", requests)
+	}
+
+	// In a plan, only the calls other than Read fail.
+	plan, _ := visionkit.NewPlan("tags", "read")
+	result, err := client.RunPlan(context.Background(), document, plan)
+	if !errors.Is(err, visionkit.ErrPDFUnsupported) {
+		t.Errorf("RunPlan error = %v, want %v", err, visionkit.ErrPDFUnsupported)
+	}
+	if result.Err(visionkit.CapabilityRead) != nil || result.Read == nil {
+		t.Errorf("read error = %v, want the read call to succeed", result.Err(visionkit.CapabilityRead))
+	}
+}
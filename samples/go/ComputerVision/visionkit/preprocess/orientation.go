@@ -0,0 +1,172 @@
+package preprocess
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+)
+
+// Values of the EXIF orientation tag, named after what must be done to the
+// stored pixels to display them upright.
+const (
+	OrientationNormal     = 1
+	OrientationFlipH      = 2
+	OrientationRotate180  = 3
+	OrientationFlipV      = 4
+	OrientationTranspose  = 5
+	OrientationRotate90   = 6
+	OrientationTransverse = 7
+	OrientationRotate270  = 8
+)
+
+// exifOrientationTag is the tag of the orientation in the first IFD.
+const exifOrientationTag = 0x0112
+
+// Orientation returns the EXIF orientation of a JPEG or TIFF image, which
+// tells how the camera was held, or OrientationNormal when there is none.
+func Orientation(data []byte) int {
+	var orientation int
+	switch Sniff(data) {
+	case FormatJPEG:
+		orientation = tiffOrientation(jpegExif(data))
+	case FormatTIFF:
+		orientation = tiffOrientation(data)
+	}
+	if orientation < OrientationNormal || orientation > OrientationRotate270 {
+		return OrientationNormal
+	}
+	return orientation
+}
+
+// Orient returns img turned the way it is displayed, given its EXIF
+// orientation. A sideways orientation swaps the width and the height.
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation <= OrientationNormal || orientation > OrientationRotate270 {
+		return img
+	}
+	bounds := img.Bounds()
+	src, ok := img.(*image.RGBA)
+	if !ok || bounds.Min != (image.Point{}) {
+		src = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(src, src.Bounds(), img, bounds.Min, draw.Src)
+	}
+	width, height := bounds.Dx(), bounds.Dy()
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	if orientation >= OrientationTranspose {
+		dst = image.NewRGBA(image.Rect(0, 0, height, width))
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case OrientationFlipH:
+				dx, dy = width-1-x, y
+			case OrientationRotate180:
+				dx, dy = width-1-x, height-1-y
+			case OrientationFlipV:
+				dx, dy = x, height-1-y
+			case OrientationTranspose:
+				dx, dy = y, x
+			case OrientationRotate90:
+				dx, dy = height-1-y, x
+			case OrientationTransverse:
+				dx, dy = height-1-y, width-1-x
+			case OrientationRotate270:
+				dx, dy = y, width-1-x
+			}
+			s, d := src.PixOffset(x, y), dst.PixOffset(dx, dy)
+			copy(dst.Pix[d:d+4], src.Pix[s:s+4])
+		}
+	}
+	return dst
+}
+
+// OrientRect returns the place of r, a rectangle of an image of the given
+// stored size, in the image turned by Orient.
+func OrientRect(r image.Rectangle, orientation, width, height int) image.Rectangle {
+	r = r.Canon()
+	switch orientation {
+	case OrientationFlipH:
+		return image.Rect(width-r.Max.X, r.Min.Y, width-r.Min.X, r.Max.Y)
+	case OrientationRotate180:
+		return image.Rect(width-r.Max.X, height-r.Max.Y, width-r.Min.X, height-r.Min.Y)
+	case OrientationFlipV:
+		return image.Rect(r.Min.X, height-r.Max.Y, r.Max.X, height-r.Min.Y)
+	case OrientationTranspose:
+		return image.Rect(r.Min.Y, r.Min.X, r.Max.Y, r.Max.X)
+	case OrientationRotate90:
+		return image.Rect(height-r.Max.Y, r.Min.X, height-r.Min.Y, r.Max.X)
+	case OrientationTransverse:
+		return image.Rect(height-r.Max.Y, width-r.Max.X, height-r.Min.Y, width-r.Min.X)
+	case OrientationRotate270:
+		return image.Rect(r.Min.Y, width-r.Max.X, r.Max.Y, width-r.Min.X)
+	}
+	return r
+}
+
+// jpegExif returns the TIFF structure of the EXIF segment of a JPEG image,
+// or nil if there is none.
+func jpegExif(data []byte) []byte {
+	for offset := 2; offset+4 <= len(data); {
+		if data[offset] != 0xff {
+			return nil
+		}
+		marker := data[offset+1]
+		// Fill bytes and markers without a length.
+		if marker == 0xff || marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7) {
+			offset++
+			continue
+		}
+		// The image data starts after SOS, and EOI ends the image.
+		if marker == 0xda || marker == 0xd9 {
+			return nil
+		}
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+		end := offset + 2 + length
+		if length < 2 || end > len(data) {
+			return nil
+		}
+		segment := data[offset+4 : end]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return segment[6:]
+		}
+		offset = end
+	}
+	return nil
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of a TIFF
+// structure, or returns 0.
+func tiffOrientation(data []byte) int {
+	if len(data) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(data[4:]))
+	if ifd < 8 || ifd+2 > len(data) {
+		return 0
+	}
+	entries := int(order.Uint16(data[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + 12*i
+		if entry+12 > len(data) {
+			return 0
+		}
+		// The value of a single SHORT is in the first two bytes of the
+		// value field.
+		if order.Uint16(data[entry:]) == exifOrientationTag {
+			return int(order.Uint16(data[entry+8:]))
+		}
+	}
+	return 0
+}
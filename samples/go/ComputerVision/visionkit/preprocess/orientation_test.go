@@ -0,0 +1,98 @@
+package preprocess_test
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/preprocess"
+)
+
+// withOrientation returns a JPEG image with an EXIF segment that holds
+// orientation, in the byte order of a little-endian TIFF structure.
+func withOrientation(jpegData []byte, orientation int) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	ifd := make([]byte, 2+12+4)
+	binary.LittleEndian.PutUint16(ifd, 1)
+	binary.LittleEndian.PutUint16(ifd[2:], 0x0112)
+	binary.LittleEndian.PutUint16(ifd[4:], 3) // SHORT
+	binary.LittleEndian.PutUint32(ifd[6:], 1)
+	binary.LittleEndian.PutUint16(ifd[10:], uint16(orientation))
+	exif := append([]byte("Exif\x00\x00"), append(tiff, ifd...)...)
+
+	segment := []byte{0xff, 0xe1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+len(exif)))
+	segment = append(segment, exif...)
+
+	data := append([]byte{}, jpegData[:2]...)
+	data = append(data, segment...)
+	return append(data, jpegData[2:]...)
+}
+
+func TestOrientation(t *testing.T) {
+	jpegData := encoded(t, opaqueImage(8, 8), preprocess.FormatJPEG)
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"no exif", jpegData, preprocess.OrientationNormal},
+		{"rotated", withOrientation(jpegData, preprocess.OrientationRotate90), preprocess.OrientationRotate90},
+		{"transverse", withOrientation(jpegData, preprocess.OrientationTransverse), preprocess.OrientationTransverse},
+		{"out of range", withOrientation(jpegData, 9), preprocess.OrientationNormal},
+		{"png", encoded(t, opaqueImage(8, 8), preprocess.FormatPNG), preprocess.OrientationNormal},
+	}
+	for _, test := range tests {
+		if got := preprocess.Orientation(test.data); got != test.want {
+			t.Errorf("Orientation(%v) = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// marked returns the bounds of the red pixels of img.
+func marked(img image.Image) image.Rectangle {
+	var r image.Rectangle
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if red, _, _, _ := img.At(x, y).RGBA(); red == 0xffff {
+				r = r.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+	return r
+}
+
+func TestOrientRect(t *testing.T) {
+	const width, height = 7, 4
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	box := image.Rect(1, 0, 4, 2)
+	for y := box.Min.Y; y < box.Max.Y; y++ {
+		for x := box.Min.X; x < box.Max.X; x++ {
+			img.Set(x, y, color.RGBA{R: 0xff, A: 0xff})
+		}
+	}
+
+	for orientation := preprocess.OrientationNormal; orientation <= preprocess.OrientationRotate270; orientation++ {
+		want := marked(preprocess.Orient(img, orientation))
+		if got := preprocess.OrientRect(box, orientation, width, height); got != want {
+			t.Errorf("OrientRect(%v, %v) = %v, want %v where Orient moved the pixels", box, orientation, got, want)
+		}
+	}
+}
+
+func TestPreprocessTurnsUpright(t *testing.T) {
+	data := withOrientation(encoded(t, opaqueImage(120, 80), preprocess.FormatJPEG), preprocess.OrientationRotate90)
+	upright, scale, err := preprocess.Preprocessor{}.Preprocess(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := preprocess.Inspect(upright)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Width != 80 || info.Height != 120 || info.Orientation != preprocess.OrientationNormal || scale != 1 {
+		t.Errorf("got a %vx%v image with orientation %v at scale %v, want an upright 80x120 one at 1", info.Width, info.Height, info.Orientation, scale)
+	}
+}
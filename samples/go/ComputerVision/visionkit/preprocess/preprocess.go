@@ -0,0 +1,323 @@
+// Package preprocess checks local images against the limits of the Computer
+// Vision API before they are uploaded, and shrinks or converts the ones that
+// do not fit, so they do not fail on the service after a billed round trip.
+// Set a Preprocessor on a visionkit.Client and every local image goes
+// through it; the client scales the coordinates of the results back to the
+// original image:
+//
+//	client.Preprocessor = preprocess.Preprocessor{}
+//
+// Images that already fit are uploaded unchanged. Others are decoded and
+// re-encoded: downscaled when a side or the file size is over the limit,
+// converted to JPEG or PNG when the service does not take their format, and
+// turned upright when their EXIF orientation says the camera was held
+// sideways or upside down.
+package preprocess
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+
+	// Decoders of the formats the standard library does not have.
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// Errors returned by Preprocess. They are wrapped with the details of the
+// image.
+var (
+	ErrUnsupportedFormat = errors.New("preprocess: unsupported image format")
+	ErrTooSmall          = errors.New("preprocess: image is smaller than the service accepts")
+	ErrTooLarge          = errors.New("preprocess: image cannot be made to fit the service limits")
+)
+
+// Limits of the Computer Vision API 2.0 for uploaded images. MaxDimension is
+// the limit of OCR, the strictest of the operations.
+const (
+	MaxBytes     = 4 << 20
+	MinDimension = 50
+	MaxDimension = 4200
+)
+
+// JPEGQuality is the default quality of re-encoded JPEG images.
+const JPEGQuality = 90
+
+// Image formats returned by Sniff.
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+	FormatGIF  = "gif"
+	FormatBMP  = "bmp"
+	FormatTIFF = "tiff"
+	FormatWebP = "webp"
+	FormatHEIF = "heif"
+	FormatPDF  = "pdf"
+)
+
+// accepted are the formats the service takes as they are.
+var accepted = map[string]bool{
+	FormatJPEG: true,
+	FormatPNG:  true,
+	FormatGIF:  true,
+	FormatBMP:  true,
+}
+
+// shrinkFactor is how much the sides of an image are reduced each time the
+// encoded image is still over MaxBytes.
+const shrinkFactor = 0.75
+
+// maxShrinks caps the attempts to get an image under MaxBytes.
+const maxShrinks = 8
+
+// Preprocessor fits images to the limits of the service. The zero value uses
+// the defaults listed on each field.
+type Preprocessor struct {
+	// MaxBytes is the largest image uploaded. Defaults to MaxBytes.
+	MaxBytes int
+	// MinDimension is the shortest side accepted. Defaults to MinDimension.
+	MinDimension int
+	// MaxDimension is the longest side uploaded; larger images are
+	// downscaled. Defaults to MaxDimension.
+	MaxDimension int
+	// JPEGQuality is the quality of re-encoded JPEG images. Defaults to
+	// JPEGQuality.
+	JPEGQuality int
+}
+
+// Info describes an image without decoding its pixels. Width and Height are
+// those of the stored pixels, before Orientation is applied.
+type Info struct {
+	Format      string
+	Width       int
+	Height      int
+	Bytes       int
+	Orientation int
+}
+
+// Inspect sniffs the format of an image and reads its size.
+func Inspect(data []byte) (Info, error) {
+	info := Info{Format: Sniff(data), Bytes: len(data)}
+	switch info.Format {
+	case "":
+		return info, fmt.Errorf("%w: not a JPEG, PNG, GIF, BMP, TIFF, or WebP image", ErrUnsupportedFormat)
+	case FormatHEIF:
+		return info, fmt.Errorf("%w: HEIF images cannot be decoded; convert the image to JPEG first", ErrUnsupportedFormat)
+	case FormatPDF:
+		return info, fmt.Errorf("%w: PDF documents have no pixel size", ErrUnsupportedFormat)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return info, fmt.Errorf("%w: %v image: %v", ErrUnsupportedFormat, info.Format, err)
+	}
+	info.Width, info.Height = config.Width, config.Height
+	info.Orientation = Orientation(data)
+	return info, nil
+}
+
+// Sniff returns the format of an image or document from its first bytes, or
+// "" if it is not one of the formats listed above.
+func Sniff(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return FormatJPEG
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return FormatPNG
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return FormatGIF
+	case isBMP(data):
+		return FormatBMP
+	case bytes.HasPrefix(data, []byte("II*\x00")), bytes.HasPrefix(data, []byte("MM\x00*")):
+		return FormatTIFF
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return FormatPDF
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return FormatWebP
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		switch string(data[8:12]) {
+		case "heic", "heix", "hevc", "hevx", "heim", "heis", "mif1", "msf1", "avif":
+			return FormatHEIF
+		}
+	}
+	return ""
+}
+
+// isBMP checks the "BM" signature and the size of the DIB header that
+// follows the 14-byte file header, which is one of the sizes defined by the
+// versions of the format, so that other data starting with "BM" is not
+// taken for a bitmap.
+func isBMP(data []byte) bool {
+	if len(data) < 18 || !bytes.HasPrefix(data, []byte("BM")) {
+		return false
+	}
+	switch binary.LittleEndian.Uint32(data[14:18]) {
+	case 12, 16, 40, 52, 56, 64, 108, 124:
+		return true
+	}
+	return false
+}
+
+// Preprocess returns the image to upload in place of data, and the factor
+// its sides were scaled by, which is 1 when data is returned unchanged.
+// Coordinates found in the returned image are divided by the factor to map
+// them back to data.
+//
+// An image with an EXIF orientation is turned upright before upload, and
+// the tag is dropped, so the coordinates are those of the image as it is
+// displayed rather than as its pixels are stored.
+//
+// PDF documents, which only the Read API takes, are returned unchanged; a
+// visionkit.Client refuses them for the other tasks before calling
+// Preprocess. A multi-page TIFF image is converted to its first page.
+func (p Preprocessor) Preprocess(data []byte) ([]byte, float64, error) {
+	if Sniff(data) == FormatPDF {
+		return data, 1, nil
+	}
+	info, err := Inspect(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if info.Width < p.minDimension() || info.Height < p.minDimension() {
+		return nil, 0, fmt.Errorf("%w: %vx%v, the shortest side must be at least %v pixels", ErrTooSmall, info.Width, info.Height, p.minDimension())
+	}
+	longest := info.Width
+	if info.Height > longest {
+		longest = info.Height
+	}
+	upright := info.Orientation == OrientationNormal
+	if upright && accepted[info.Format] && info.Bytes <= p.maxBytes() && longest <= p.maxDimension() {
+		return data, 1, nil
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v image: %v", ErrUnsupportedFormat, info.Format, err)
+	}
+	src = Orient(src, info.Orientation)
+	width := src.Bounds().Dx()
+	scale := math.Min(1, float64(p.maxDimension())/float64(longest))
+	format := FormatJPEG
+	if info.Format == FormatPNG || info.Format == FormatGIF || !opaque(src) {
+		format = FormatPNG
+	}
+
+	for shrinks := 0; shrinks <= maxShrinks; shrinks++ {
+		img := resize(src, scale)
+		bounds := img.Bounds()
+		if bounds.Dx() < p.minDimension() || bounds.Dy() < p.minDimension() {
+			break
+		}
+		encoded, err := p.encode(img, format)
+		if err != nil {
+			return nil, 0, err
+		}
+		// A PNG that is too large is first tried as a JPEG at the same size.
+		if len(encoded) > p.maxBytes() && format == FormatPNG {
+			format = FormatJPEG
+			if encoded, err = p.encode(img, format); err != nil {
+				return nil, 0, err
+			}
+		}
+		if len(encoded) <= p.maxBytes() {
+			return encoded, float64(bounds.Dx()) / float64(width), nil
+		}
+		scale *= shrinkFactor
+	}
+	return nil, 0, fmt.Errorf("%w: %v %vx%v image of %v bytes", ErrTooLarge, info.Format, info.Width, info.Height, info.Bytes)
+}
+
+// resize returns the image scaled by factor, or src itself for a factor of 1
+// or more.
+func resize(src image.Image, factor float64) image.Image {
+	if factor >= 1 {
+		return src
+	}
+	bounds := src.Bounds()
+	width := int(math.Round(float64(bounds.Dx()) * factor))
+	height := int(math.Round(float64(bounds.Dy()) * factor))
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, xdraw.Src, nil)
+	return dst
+}
+
+func (p Preprocessor) encode(img image.Image, format string) ([]byte, error) {
+	var encoded bytes.Buffer
+	var err error
+	if format == FormatPNG {
+		err = png.Encode(&encoded, img)
+	} else {
+		err = jpeg.Encode(&encoded, flatten(img), &jpeg.Options{Quality: p.jpegQuality()})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("preprocess: encoding the image as %v: %w", format, err)
+	}
+	return encoded.Bytes(), nil
+}
+
+// flatten draws an image with transparent parts onto white, since JPEG has
+// no transparency.
+func flatten(img image.Image) image.Image {
+	if opaque(img) {
+		return img
+	}
+	dst := image.NewRGBA(img.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, img.Bounds().Min, draw.Over)
+	return dst
+}
+
+// opaque reports whether an image has no transparent pixels. Images that
+// cannot tell are assumed to have some.
+func opaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// String describes the settings of p, the defaults filled in, for example
+// "maxBytes=4194304 minDimension=50 maxDimension=4200 jpegQuality=90". The
+// visionkit result cache keys results by it.
+func (p Preprocessor) String() string {
+	return fmt.Sprintf("maxBytes=%v minDimension=%v maxDimension=%v jpegQuality=%v",
+		p.maxBytes(), p.minDimension(), p.maxDimension(), p.jpegQuality())
+}
+
+func (p Preprocessor) maxBytes() int {
+	if p.MaxBytes > 0 {
+		return p.MaxBytes
+	}
+	return MaxBytes
+}
+
+func (p Preprocessor) minDimension() int {
+	if p.MinDimension > 0 {
+		return p.MinDimension
+	}
+	return MinDimension
+}
+
+func (p Preprocessor) maxDimension() int {
+	if p.MaxDimension > 0 {
+		return p.MaxDimension
+	}
+	return MaxDimension
+}
+
+func (p Preprocessor) jpegQuality() int {
+	if p.JPEGQuality > 0 {
+		return p.JPEGQuality
+	}
+	return JPEGQuality
+}
@@ -0,0 +1,214 @@
+package preprocess_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/preprocess"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+)
+
+// opaqueImage returns an image of the given size filled with noise, which
+// does not compress.
+func opaqueImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	random := rand.New(rand.NewSource(1))
+	for i := range img.Pix {
+		img.Pix[i] = byte(random.Intn(256))
+		if i%4 == 3 {
+			img.Pix[i] = 0xff
+		}
+	}
+	return img
+}
+
+// encoded returns img in the given format.
+func encoded(t *testing.T, img image.Image, format string) []byte {
+	t.Helper()
+	var data bytes.Buffer
+	var err error
+	switch format {
+	case preprocess.FormatJPEG:
+		err = jpeg.Encode(&data, img, nil)
+	case preprocess.FormatPNG:
+		err = png.Encode(&data, img)
+	case preprocess.FormatGIF:
+		err = gif.Encode(&data, img, nil)
+	case preprocess.FormatBMP:
+		err = bmp.Encode(&data, img)
+	case preprocess.FormatTIFF:
+		err = tiff.Encode(&data, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data.Bytes()
+}
+
+func TestSniff(t *testing.T) {
+	img := opaqueImage(8, 8)
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"jpeg", encoded(t, img, preprocess.FormatJPEG), preprocess.FormatJPEG},
+		{"png", encoded(t, img, preprocess.FormatPNG), preprocess.FormatPNG},
+		{"gif", encoded(t, img, preprocess.FormatGIF), preprocess.FormatGIF},
+		{"bmp", encoded(t, img, preprocess.FormatBMP), preprocess.FormatBMP},
+		{"tiff", encoded(t, img, preprocess.FormatTIFF), preprocess.FormatTIFF},
+		{"pdf", []byte("%PDF-1.7\n"), preprocess.FormatPDF},
+		{"webp", []byte("RIFF\x24\x00\x00\x00WEBPVP8 "), preprocess.FormatWebP},
+		{"heic", []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00"), preprocess.FormatHEIF},
+		{"text starting with BM", []byte("BM hello, this is not a bitmap"), ""},
+		{"bmp header cut short", []byte("BM\x00\x00"), ""},
+		{"mp4", []byte("\x00\x00\x00\x18ftypisom\x00\x00\x00\x00"), ""},
+		{"empty", nil, ""},
+	}
+	for _, test := range tests {
+		if got := preprocess.Sniff(test.data); got != test.want {
+			t.Errorf("Sniff(%v) = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestInspect(t *testing.T) {
+	info, err := preprocess.Inspect(encoded(t, opaqueImage(120, 80), preprocess.FormatPNG))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Format != preprocess.FormatPNG || info.Width != 120 || info.Height != 80 || info.Orientation != preprocess.OrientationNormal {
+		t.Errorf("Inspect = %+v, want a 120x80 upright PNG", info)
+	}
+	for _, data := range [][]byte{[]byte("%PDF-1.7"), []byte("plain text"), []byte("\x89PNG\r\n\x1a\nbroken")} {
+		if _, err := preprocess.Inspect(data); !errors.Is(err, preprocess.ErrUnsupportedFormat) {
+			t.Errorf("Inspect(%q) error = %v, want %v", data, err, preprocess.ErrUnsupportedFormat)
+		}
+	}
+}
+
+func TestPreprocess(t *testing.T) {
+	tests := []struct {
+		name         string
+		preprocessor preprocess.Preprocessor
+		data         []byte
+		unchanged    bool
+		wantFormat   string
+		wantWidth    int
+		wantHeight   int
+		wantScale    float64
+		wantErr      error
+	}{
+		{
+			name:      "fits",
+			data:      encoded(t, opaqueImage(120, 80), preprocess.FormatPNG),
+			unchanged: true,
+			wantScale: 1,
+		},
+		{
+			name:      "pdf",
+			data:      []byte("%PDF-1.7\n"),
+			unchanged: true,
+			wantScale: 1,
+		},
+		{
+			name:         "downscaled",
+			preprocessor: preprocess.Preprocessor{MaxDimension: 100},
+			data:         encoded(t, opaqueImage(400, 200), preprocess.FormatPNG),
+			wantFormat:   preprocess.FormatPNG,
+			wantWidth:    100,
+			wantHeight:   50,
+			wantScale:    0.25,
+		},
+		{
+			name:       "converted",
+			data:       encoded(t, opaqueImage(120, 80), preprocess.FormatTIFF),
+			wantFormat: preprocess.FormatJPEG,
+			wantWidth:  120,
+			wantHeight: 80,
+			wantScale:  1,
+		},
+		{
+			name:         "shrunk under the size limit as a jpeg",
+			preprocessor: preprocess.Preprocessor{MaxBytes: 40000},
+			data:         encoded(t, opaqueImage(400, 400), preprocess.FormatPNG),
+			wantFormat:   preprocess.FormatJPEG,
+			wantWidth:    -1,
+		},
+		{
+			name:    "too small",
+			data:    encoded(t, opaqueImage(40, 400), preprocess.FormatPNG),
+			wantErr: preprocess.ErrTooSmall,
+		},
+		{
+			name:         "cannot fit",
+			preprocessor: preprocess.Preprocessor{MaxBytes: 100},
+			data:         encoded(t, opaqueImage(200, 200), preprocess.FormatPNG),
+			wantErr:      preprocess.ErrTooLarge,
+		},
+		{
+			name:    "not an image",
+			data:    []byte("plain text"),
+			wantErr: preprocess.ErrUnsupportedFormat,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, scale, err := test.preprocessor.Preprocess(test.data)
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Errorf("error = %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.unchanged {
+				if !bytes.Equal(data, test.data) || scale != test.wantScale {
+					t.Errorf("Preprocess changed the image (scale %v), want it unchanged", scale)
+				}
+				return
+			}
+
+			info, err := preprocess.Inspect(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Format != test.wantFormat {
+				t.Errorf("format = %v, want %v", info.Format, test.wantFormat)
+			}
+			if test.wantWidth < 0 {
+				// Only the size limit and the scale matter.
+				if info.Bytes > test.preprocessor.MaxBytes || scale >= 1 || int(math.Round(400*scale)) != info.Width {
+					t.Errorf("got a %vx%v image of %v bytes at scale %v, want it under %v bytes", info.Width, info.Height, info.Bytes, scale, test.preprocessor.MaxBytes)
+				}
+				return
+			}
+			if info.Width != test.wantWidth || info.Height != test.wantHeight || scale != test.wantScale {
+				t.Errorf("got a %vx%v image at scale %v, want %vx%v at %v", info.Width, info.Height, scale, test.wantWidth, test.wantHeight, test.wantScale)
+			}
+		})
+	}
+}
+
+func TestPreprocessTransparent(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 300, 100))
+	img.Set(10, 10, color.NRGBA{R: 0xff, A: 0x80})
+	data, _, err := preprocess.Preprocessor{MaxDimension: 150}.Preprocess(encoded(t, img, preprocess.FormatTIFF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format := preprocess.Sniff(data); format != preprocess.FormatPNG {
+		t.Errorf("format = %v, want a PNG to keep the transparency", format)
+	}
+}
@@ -0,0 +1,224 @@
+// Package ratelimit keeps a program under the transactions-per-second limit
+// of a Computer Vision subscription. A Limiter is a token bucket shared by
+// every goroutine that calls the same endpoint; set it as the Limiter of a
+// visionkit.Client and every HTTP request the client sends, read operation
+// polls and retries included, waits for a token first:
+//
+//	client.Limiter = ratelimit.Shared(endpointURL, ratelimit.Limit{Rate: 10})
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit is the rate of a Limiter.
+type Limit struct {
+	// Rate is the sustained number of requests per second.
+	Rate float64
+	// Burst is the number of requests that can be sent at once after a
+	// quiet period. Defaults to Rate rounded up, and at least 1.
+	Burst int
+}
+
+func (l Limit) burst() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return math.Max(1, math.Ceil(l.Rate))
+}
+
+// ParseRate parses a rate such as "10", "10/s", "20/m", or "5000/h" into
+// requests per second.
+func ParseRate(text string) (float64, error) {
+	count, unit := strings.TrimSpace(text), "s"
+	if i := strings.Index(count, "/"); i >= 0 {
+		count, unit = strings.TrimSpace(count[:i]), strings.TrimSpace(count[i+1:])
+	}
+	n, err := strconv.ParseFloat(count, 64)
+	if err != nil || n <= 0 || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("ratelimit: invalid rate %q", text)
+	}
+	switch unit {
+	case "s":
+		return n, nil
+	case "m":
+		return n / 60, nil
+	case "h":
+		return n / 3600, nil
+	}
+	return 0, fmt.Errorf("ratelimit: invalid rate %q (want a number per s, m, or h)", text)
+}
+
+// Stats describe the requests that went through a Limiter.
+type Stats struct {
+	// Requests is the number of tokens handed out.
+	Requests int64
+	// Delayed is the number of requests that had to wait for their token.
+	Delayed int64
+	// Waiting is the number of requests waiting now, and MaxWaiting the
+	// highest it has been.
+	Waiting    int
+	MaxWaiting int
+	// TotalWait and MaxWait are the sum and the longest of the waits.
+	TotalWait time.Duration
+	MaxWait   time.Duration
+}
+
+// AverageWait is the mean wait over all the requests.
+func (s Stats) AverageWait() time.Duration {
+	if s.Requests == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(s.Requests)
+}
+
+// Limiter is a token bucket. Requests get their tokens in the order they
+// ask, so none of them starves. It is safe for concurrent use.
+type Limiter struct {
+	mu     sync.Mutex
+	limit  Limit
+	tokens float64
+	last   time.Time
+	stats  Stats
+}
+
+// New returns a Limiter whose bucket starts full.
+func New(limit Limit) *Limiter {
+	return &Limiter{limit: limit, tokens: limit.burst(), last: time.Now()}
+}
+
+// SetLimit changes the rate, keeping the tokens already in the bucket.
+func (l *Limiter) SetLimit(limit Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.advance(time.Now())
+	l.limit = limit
+	l.tokens = math.Min(l.tokens, limit.burst())
+}
+
+// Limit returns the current rate.
+func (l *Limiter) Limit() Limit {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// Stats returns a snapshot of the statistics.
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// Wait blocks until a request may be sent or ctx is done. A Limiter with a
+// zero Rate lets everything through.
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	if l.limit.Rate <= 0 {
+		l.stats.Requests++
+		l.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	l.advance(now)
+	// Reserve the token now, even if the bucket goes negative: the debt is
+	// what orders the requests waiting behind this one.
+	l.tokens--
+	wait := time.Duration(0)
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.limit.Rate * float64(time.Second))
+	}
+	if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+		l.tokens++
+		l.mu.Unlock()
+		return fmt.Errorf("ratelimit: waiting %v would pass the deadline: %w", wait.Round(time.Millisecond), context.DeadlineExceeded)
+	}
+	if wait == 0 {
+		l.stats.Requests++
+		l.mu.Unlock()
+		return nil
+	}
+	l.stats.Waiting++
+	if l.stats.Waiting > l.stats.MaxWaiting {
+		l.stats.MaxWaiting = l.stats.Waiting
+	}
+	l.mu.Unlock()
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	var err error
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats.Waiting--
+	if err != nil {
+		l.tokens++
+		return err
+	}
+	l.stats.Requests++
+	l.stats.Delayed++
+	l.stats.TotalWait += wait
+	if wait > l.stats.MaxWait {
+		l.stats.MaxWait = wait
+	}
+	return nil
+}
+
+// advance adds the tokens earned since the last call.
+func (l *Limiter) advance(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = math.Min(l.limit.burst(), l.tokens+elapsed.Seconds()*l.limit.Rate)
+	}
+	l.last = now
+}
+
+var shared = struct {
+	sync.Mutex
+	limiters map[string]*Limiter
+}{limiters: map[string]*Limiter{}}
+
+// Shared returns the Limiter of an endpoint, shared by every client of the
+// process that calls it. Endpoints are compared by host, so
+// "https://westus.api.cognitive.microsoft.com/" and its variants share one
+// Limiter. The first call creates it; later calls with a different limit
+// change its rate.
+func Shared(endpointURL string, limit Limit) *Limiter {
+	key := endpointKey(endpointURL)
+	shared.Lock()
+	defer shared.Unlock()
+	limiter, ok := shared.limiters[key]
+	if !ok {
+		limiter = New(limit)
+		shared.limiters[key] = limiter
+	} else if limiter.Limit() != limit {
+		limiter.SetLimit(limit)
+	}
+	return limiter
+}
+
+// Lookup returns the shared Limiter of an endpoint, if there is one.
+func Lookup(endpointURL string) (*Limiter, bool) {
+	shared.Lock()
+	defer shared.Unlock()
+	limiter, ok := shared.limiters[endpointKey(endpointURL)]
+	return limiter, ok
+}
+
+func endpointKey(endpointURL string) string {
+	if u, err := url.Parse(endpointURL); err == nil && u.Host != "" {
+		return strings.ToLower(u.Host)
+	}
+	return strings.ToLower(strings.TrimRight(endpointURL, "/"))
+}
@@ -0,0 +1,127 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/ratelimit"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    float64
+		wantErr bool
+	}{
+		{text: "10", want: 10},
+		{text: " 2.5/s ", want: 2.5},
+		{text: "20/m", want: 20.0 / 60},
+		{text: "5000 / h", want: 5000.0 / 3600},
+		{text: "", wantErr: true},
+		{text: "fast", wantErr: true},
+		{text: "0", wantErr: true},
+		{text: "-1/s", wantErr: true},
+		{text: "10/d", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := ratelimit.ParseRate(test.text)
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("ParseRate(%q) = %v, %v, want %v", test.text, got, err, test.want)
+		}
+	}
+}
+
+func TestLimiterBurst(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Limit{Rate: 50, Burst: 3})
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The burst goes out at once, and the two requests after it wait 20ms
+	// each.
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("5 requests took %v, want about 40ms past a burst of 3 at 50/s", elapsed)
+	}
+	stats := limiter.Stats()
+	if stats.Requests != 5 || stats.Delayed != 2 || stats.Waiting != 0 {
+		t.Errorf("stats = %+v, want 5 requests with 2 delayed", stats)
+	}
+	if stats.MaxWait < stats.AverageWait() || stats.TotalWait < stats.MaxWait {
+		t.Errorf("stats = %+v, want the total over the max over the average", stats)
+	}
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Limit{})
+	for i := 0; i < 100; i++ {
+		limiter.Wait(context.Background())
+	}
+	if stats := limiter.Stats(); stats.Requests != 100 || stats.Delayed != 0 {
+		t.Errorf("stats = %+v, want 100 requests and none delayed", stats)
+	}
+}
+
+func TestLimiterDeadline(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Limit{Rate: 1})
+	limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Wait took %v, want it to fail at once when the wait is past the deadline", elapsed)
+	}
+	if stats := limiter.Stats(); stats.Requests != 1 {
+		t.Errorf("requests = %v, want the refused one not counted", stats.Requests)
+	}
+}
+
+func TestShared(t *testing.T) {
+	limit := ratelimit.Limit{Rate: 10}
+	a := ratelimit.Shared("https://WestUS.api.cognitive.microsoft.com/", limit)
+	b := ratelimit.Shared("https://westus.api.cognitive.microsoft.com/vision/v3.2", limit)
+	if a != b {
+		t.Error("Shared returned two limiters for the same host")
+	}
+	if c := ratelimit.Shared("https://eastus.api.cognitive.microsoft.com", limit); c == a {
+		t.Error("Shared returned the same limiter for two hosts")
+	}
+
+	ratelimit.Shared("https://westus.api.cognitive.microsoft.com", ratelimit.Limit{Rate: 2})
+	if got := a.Limit(); got.Rate != 2 {
+		t.Errorf("rate = %v, want the later limit", got.Rate)
+	}
+	if got, ok := ratelimit.Lookup("https://westus.api.cognitive.microsoft.com"); !ok || got != a {
+		t.Error("Lookup did not find the shared limiter")
+	}
+	if _, ok := ratelimit.Lookup("https://northeurope.api.cognitive.microsoft.com"); ok {
+		t.Error("Lookup found a limiter for an endpoint never shared")
+	}
+}
+
+func TestClientLimiter(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	server.ReadPolls = 2
+	client := server.Client()
+	limiter := ratelimit.New(ratelimit.Limit{Rate: 1000})
+	client.Limiter = limiter
+
+	if _, err := client.ReadText(context.Background(), visionkit.URL("https://example.com/receipt.jpg"), computervision.Printed); err != nil {
+		t.Fatal(err)
+	}
+	want := int64(len(server.Requests()))
+	if stats := limiter.Stats(); stats.Requests != want {
+		t.Errorf("tokens = %v, want one for each of the %v requests, polls included", stats.Requests, want)
+	}
+}
@@ -0,0 +1,102 @@
+package visionkit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// ReadText recognizes printed or handwritten text in an image with the batch
+// Read API. It submits the image and then waits for the read operation to
+// complete. Use StartReadText and ResumeReadText to split the two steps.
+func (c *Client) ReadText(ctx context.Context, image ImageSource, mode computervision.TextRecognitionMode) (ReadResult, error) {
+	var readResult ReadResult
+	err := c.cached(ctx, "read", image, []string{strings.ToLower(string(mode))}, &readResult, func(image ImageSource) error {
+		operation, err := c.startReadText(ctx, image, mode)
+		if err != nil {
+			return err
+		}
+		readResult, err = c.ResumeReadText(ctx, operation)
+		return err
+	})
+	var readError *Error
+	if err != nil && !errors.As(err, &readError) {
+		err = wrapError("read", image, err)
+	}
+	return readResult, err
+}
+
+// StartReadText submits an image to the batch Read API and returns a handle
+// to the operation without waiting for it.
+//
+// When you use the Read Document interface, the response contains a field
+// called "Operation-Location", which contains the URL to use for your
+// GetReadOperationResult to access OCR results.
+//
+// With a Preprocessor, the image is prepared first and the operation keeps
+// its Scale, which is saved with it, so that ResumeReadText maps the result
+// back to the original image.
+func (c *Client) StartReadText(ctx context.Context, image ImageSource, mode computervision.TextRecognitionMode) (ReadOperation, error) {
+	prepared, err := c.prepare("read", image)
+	if err != nil {
+		return ReadOperation{}, wrapError("read", image, err)
+	}
+	operation, err := c.startReadText(ctx, prepared, mode)
+	if p, ok := prepared.(preparedImage); ok && err == nil {
+		operation.Scale = p.scale
+	}
+	return operation, err
+}
+
+// startReadText submits an image as it is.
+func (c *Client) startReadText(ctx context.Context, image ImageSource, mode computervision.TextRecognitionMode) (ReadOperation, error) {
+	textHeaders, err := c.batchReadFile(ctx, image, mode)
+	if err != nil {
+		return ReadOperation{}, wrapError("read", image, err)
+	}
+
+	operation, err := ParseOperationLocation(autorest.ExtractHeaderValue("Operation-Location", textHeaders.Response))
+	if err != nil {
+		return ReadOperation{}, wrapError("read", image, err)
+	}
+	operation.Image = image.Name()
+	return operation, nil
+}
+
+// ResumeReadText waits for an operation returned by StartReadText, or parsed
+// with ParseOperationLocation, with the client's ReadPoller. Each poll is
+// retried with the client's Retry policy unless the poller has its own.
+func (c *Client) ResumeReadText(ctx context.Context, operation ReadOperation) (ReadResult, error) {
+	poller := c.ReadPoller
+	if poller.Retry == nil {
+		poller.Retry = &c.Retry
+	}
+	readOperationResult, err := poller.Poll(ctx, c.BaseClient, operation.ID)
+	if err != nil {
+		name := operation.Image
+		if name == "" {
+			name = operation.Location
+		}
+		return ReadResult{}, &Error{Op: "read", Image: name, Err: err}
+	}
+	readResult := toReadResult(readOperationResult)
+	scaleBack(&readResult, operation.Scale)
+	return readResult, nil
+}
+
+// batchReadFile calls BatchReadFile for remote images, or
+// BatchReadFileInStream for everything else.
+func (c *Client) batchReadFile(ctx context.Context, image ImageSource, mode computervision.TextRecognitionMode) (response autorest.Response, err error) {
+	err = c.call(ctx, image, func(imageURL computervision.ImageURL) (err error) {
+		response, err = c.BaseClient.BatchReadFile(ctx, imageURL, mode)
+		return err
+	}, func(localImage io.ReadCloser) (err error) {
+		response, err = c.BaseClient.BatchReadFileInStream(ctx, localImage, mode)
+		return err
+	})
+	return response, err
+}
@@ -0,0 +1,559 @@
+package visionkit
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+)
+
+// The types in this file are the results returned by the Client. They are
+// plain values converted from the SDK models, so callers never have to
+// dereference the optional pointer fields the SDK uses. Their JSON field
+// names are part of the versioned output schema (see package output), so
+// renaming one is a schema change.
+
+// Caption is a sentence describing an image.
+type Caption struct {
+	Text       string  `json:"text"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Description is the result of Describe.
+type Description struct {
+	Captions []Caption `json:"captions"`
+	Tags     []string  `json:"tags"`
+}
+
+// Category is one of the categories from the 86-category taxonomy.
+// Celebrities and Landmarks are only filled in when the matching Details
+// were requested.
+type Category struct {
+	Name        string      `json:"name"`
+	Score       float64     `json:"score"`
+	Celebrities []Celebrity `json:"celebrities,omitempty"`
+	Landmarks   []Landmark  `json:"landmarks,omitempty"`
+}
+
+// Tag is a content tag found in an image.
+type Tag struct {
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+	Hint       string  `json:"hint,omitempty"`
+}
+
+// Face is a face found in an image.
+type Face struct {
+	Age       int           `json:"age"`
+	Gender    string        `json:"gender"`
+	Rectangle geometry.Rect `json:"rectangle"`
+}
+
+// AdultContent tells whether an image has adult or racy content.
+type AdultContent struct {
+	IsAdultContent bool    `json:"isAdultContent"`
+	AdultScore     float64 `json:"adultScore"`
+	IsRacyContent  bool    `json:"isRacyContent"`
+	RacyScore      float64 `json:"racyScore"`
+}
+
+// ColorScheme is the color scheme of an image.
+type ColorScheme struct {
+	IsBWImg                 bool     `json:"isBWImg"`
+	AccentColor             string   `json:"accentColor"`
+	DominantColorBackground string   `json:"dominantColorBackground"`
+	DominantColorForeground string   `json:"dominantColorForeground"`
+	DominantColors          []string `json:"dominantColors"`
+}
+
+// ClipArtType is the clip art classification of an image.
+type ClipArtType int
+
+// Clip art types returned by the service.
+const (
+	NotClipArt ClipArtType = iota
+	AmbiguousClipArt
+	NormalClipArt
+	GoodClipArt
+)
+
+func (t ClipArtType) String() string {
+	switch t {
+	case NotClipArt:
+		return "not clip art"
+	case AmbiguousClipArt:
+		return "ambiguously clip art"
+	case NormalClipArt:
+		return "normal clip art"
+	case GoodClipArt:
+		return "good clip art"
+	}
+	return "unknown clip art type"
+}
+
+// ImageTypes is the clip art and line drawing classification of an image.
+type ImageTypes struct {
+	ClipArtType   ClipArtType `json:"clipArtType"`
+	IsLineDrawing bool        `json:"isLineDrawing"`
+}
+
+// DetectedObject is an object found in an image. Parents holds the broader
+// categories the object belongs to in the object taxonomy of the service,
+// nearest first, such as "mammal" and then "animal" for a dog. See
+// taxonomy.go.
+type DetectedObject struct {
+	Name       string         `json:"name"`
+	Confidence float64        `json:"confidence"`
+	Rectangle  geometry.Rect  `json:"rectangle"`
+	Parents    []ObjectParent `json:"parents,omitempty"`
+}
+
+// ObjectParent is a category of the object taxonomy above a detected object,
+// with the confidence that the object belongs to it.
+type ObjectParent struct {
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Brand is a brand logo found in an image.
+type Brand struct {
+	Name       string        `json:"name"`
+	Confidence float64       `json:"confidence"`
+	Rectangle  geometry.Rect `json:"rectangle"`
+}
+
+// Celebrity is a celebrity recognized by the celebrities domain model, with
+// the location of their face.
+type Celebrity struct {
+	Name       string        `json:"name"`
+	Confidence float64       `json:"confidence"`
+	Rectangle  geometry.Rect `json:"rectangle"`
+}
+
+// Landmark is a landmark recognized by the landmarks domain model.
+type Landmark struct {
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
+// ImageMetadata is the size and format of an image, as seen by the service.
+type ImageMetadata struct {
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Format string `json:"format"`
+}
+
+// ReadWord is a word recognized by the Read API. Polygon holds its four
+// corners, clockwise from the top-left corner of the text, and Rectangle the
+// rectangle around them. Confidence is only set ("Low") for words the
+// service is unsure about.
+type ReadWord struct {
+	Text       string           `json:"text"`
+	Rectangle  geometry.Rect    `json:"rectangle"`
+	Polygon    geometry.Polygon `json:"polygon"`
+	Confidence string           `json:"confidence,omitempty"`
+}
+
+// ReadLine is a line of text recognized by the Read API, located like its
+// words.
+type ReadLine struct {
+	Text      string           `json:"text"`
+	Rectangle geometry.Rect    `json:"rectangle"`
+	Polygon   geometry.Polygon `json:"polygon"`
+	Words     []ReadWord       `json:"words"`
+}
+
+// ReadPage is one page of a Read API result.
+type ReadPage struct {
+	Page                 int        `json:"page"`
+	ClockwiseOrientation float64    `json:"clockwiseOrientation"`
+	Width                float64    `json:"width"`
+	Height               float64    `json:"height"`
+	Unit                 string     `json:"unit"`
+	Lines                []ReadLine `json:"lines"`
+}
+
+// ReadResult is the result of ReadText.
+type ReadResult struct {
+	Status string     `json:"status"`
+	Pages  []ReadPage `json:"pages"`
+}
+
+// Lines returns the lines of every page in order.
+func (r ReadResult) Lines() []ReadLine {
+	var lines []ReadLine
+	for _, page := range r.Pages {
+		lines = append(lines, page.Lines...)
+	}
+	return lines
+}
+
+// OCRWord is a word recognized by OCR.
+type OCRWord struct {
+	Text      string        `json:"text"`
+	Rectangle geometry.Rect `json:"rectangle"`
+}
+
+// OCRLine is a line of text recognized by OCR.
+type OCRLine struct {
+	Rectangle geometry.Rect `json:"rectangle"`
+	Words     []OCRWord     `json:"words"`
+}
+
+// Text joins the words of the line with spaces.
+func (l OCRLine) Text() string {
+	words := make([]string, len(l.Words))
+	for i, word := range l.Words {
+		words[i] = word.Text
+	}
+	return strings.Join(words, " ")
+}
+
+// OCRRegion is a block of text recognized by OCR.
+type OCRRegion struct {
+	Rectangle geometry.Rect `json:"rectangle"`
+	Lines     []OCRLine     `json:"lines"`
+}
+
+// OCRResult is the result of OCR. Width and Height are the size of the
+// image the locations are in, read from a local image since the service
+// does not return it, and 0 for a remote image.
+type OCRResult struct {
+	Language    string      `json:"language"`
+	TextAngle   float64     `json:"textAngle"`
+	Orientation string      `json:"orientation"`
+	Width       int         `json:"width,omitempty"`
+	Height      int         `json:"height,omitempty"`
+	Regions     []OCRRegion `json:"regions"`
+}
+
+// Lines returns the lines of every region in order.
+func (r OCRResult) Lines() []OCRLine {
+	var lines []OCRLine
+	for _, region := range r.Regions {
+		lines = append(lines, region.Lines...)
+	}
+	return lines
+}
+
+func toDescription(details *computervision.ImageDescriptionDetails) Description {
+	var description Description
+	if details == nil {
+		return description
+	}
+	if details.Captions != nil {
+		for _, caption := range *details.Captions {
+			description.Captions = append(description.Captions, Caption{
+				Text:       stringValue(caption.Text),
+				Confidence: float64Value(caption.Confidence),
+			})
+		}
+	}
+	if details.Tags != nil {
+		description.Tags = append(description.Tags, *details.Tags...)
+	}
+	return description
+}
+
+func toCategories(categories *[]computervision.Category) []Category {
+	result := []Category{}
+	if categories == nil {
+		return result
+	}
+	for _, category := range *categories {
+		c := Category{
+			Name:  stringValue(category.Name),
+			Score: float64Value(category.Score),
+		}
+		if category.Detail != nil {
+			if category.Detail.Celebrities != nil {
+				for _, celebrity := range *category.Detail.Celebrities {
+					c.Celebrities = append(c.Celebrities, toCelebrity(celebrity))
+				}
+			}
+			if category.Detail.Landmarks != nil {
+				for _, landmark := range *category.Detail.Landmarks {
+					c.Landmarks = append(c.Landmarks, Landmark{
+						Name:       stringValue(landmark.Name),
+						Confidence: float64Value(landmark.Confidence),
+					})
+				}
+			}
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+func toCelebrity(celebrity computervision.CelebritiesModel) Celebrity {
+	return Celebrity{
+		Name:       stringValue(celebrity.Name),
+		Confidence: float64Value(celebrity.Confidence),
+		Rectangle:  toFaceRectangle(celebrity.FaceRectangle),
+	}
+}
+
+func toFaceRectangle(rectangle *computervision.FaceRectangle) geometry.Rect {
+	if rectangle == nil {
+		return geometry.Rect{}
+	}
+	return geometry.Rect{
+		X: float64(int32Value(rectangle.Left)),
+		Y: float64(int32Value(rectangle.Top)),
+		W: float64(int32Value(rectangle.Width)),
+		H: float64(int32Value(rectangle.Height)),
+	}
+}
+
+func toImageMetadata(metadata *computervision.ImageMetadata) ImageMetadata {
+	if metadata == nil {
+		return ImageMetadata{}
+	}
+	return ImageMetadata{
+		Width:  int32Value(metadata.Width),
+		Height: int32Value(metadata.Height),
+		Format: stringValue(metadata.Format),
+	}
+}
+
+func toTags(tags *[]computervision.ImageTag) []Tag {
+	result := []Tag{}
+	if tags == nil {
+		return result
+	}
+	for _, tag := range *tags {
+		result = append(result, Tag{
+			Name:       stringValue(tag.Name),
+			Confidence: float64Value(tag.Confidence),
+			Hint:       stringValue(tag.Hint),
+		})
+	}
+	return result
+}
+
+func toFaces(faces *[]computervision.FaceDescription) []Face {
+	result := []Face{}
+	if faces == nil {
+		return result
+	}
+	for _, face := range *faces {
+		result = append(result, Face{
+			Age:       int32Value(face.Age),
+			Gender:    string(face.Gender),
+			Rectangle: toFaceRectangle(face.FaceRectangle),
+		})
+	}
+	return result
+}
+
+func toAdultContent(adult *computervision.AdultInfo) AdultContent {
+	if adult == nil {
+		return AdultContent{}
+	}
+	return AdultContent{
+		IsAdultContent: boolValue(adult.IsAdultContent),
+		AdultScore:     float64Value(adult.AdultScore),
+		IsRacyContent:  boolValue(adult.IsRacyContent),
+		RacyScore:      float64Value(adult.RacyScore),
+	}
+}
+
+func toColorScheme(color *computervision.ColorInfo) ColorScheme {
+	if color == nil {
+		return ColorScheme{}
+	}
+	scheme := ColorScheme{
+		IsBWImg:                 boolValue(color.IsBWImg),
+		AccentColor:             stringValue(color.AccentColor),
+		DominantColorBackground: stringValue(color.DominantColorBackground),
+		DominantColorForeground: stringValue(color.DominantColorForeground),
+	}
+	if color.DominantColors != nil {
+		scheme.DominantColors = append(scheme.DominantColors, *color.DominantColors...)
+	}
+	return scheme
+}
+
+func toImageTypes(imageType *computervision.ImageType) ImageTypes {
+	if imageType == nil {
+		return ImageTypes{}
+	}
+	return ImageTypes{
+		ClipArtType:   ClipArtType(int32Value(imageType.ClipArtType)),
+		IsLineDrawing: int32Value(imageType.LineDrawingType) == 1,
+	}
+}
+
+func toBoundingRect(rectangle *computervision.BoundingRect) geometry.Rect {
+	if rectangle == nil {
+		return geometry.Rect{}
+	}
+	return geometry.Rect{
+		X: float64(int32Value(rectangle.X)),
+		Y: float64(int32Value(rectangle.Y)),
+		W: float64(int32Value(rectangle.W)),
+		H: float64(int32Value(rectangle.H)),
+	}
+}
+
+func toObjects(objects *[]computervision.DetectedObject) []DetectedObject {
+	result := []DetectedObject{}
+	if objects == nil {
+		return result
+	}
+	for _, object := range *objects {
+		result = append(result, DetectedObject{
+			Name:       stringValue(object.Object),
+			Confidence: float64Value(object.Confidence),
+			Rectangle:  toBoundingRect(object.Rectangle),
+			Parents:    toObjectParents(object.Parent),
+		})
+	}
+	return result
+}
+
+// toObjectParents flattens the chain of parents of an object, nearest first.
+func toObjectParents(parent *computervision.ObjectHierarchy) []ObjectParent {
+	var parents []ObjectParent
+	for ; parent != nil; parent = parent.Parent {
+		parents = append(parents, ObjectParent{
+			Name:       stringValue(parent.Object),
+			Confidence: float64Value(parent.Confidence),
+		})
+	}
+	return parents
+}
+
+func toBrands(brands *[]computervision.DetectedBrand) []Brand {
+	result := []Brand{}
+	if brands == nil {
+		return result
+	}
+	for _, brand := range *brands {
+		result = append(result, Brand{
+			Name:       stringValue(brand.Name),
+			Confidence: float64Value(brand.Confidence),
+			Rectangle:  toBoundingRect(brand.Rectangle),
+		})
+	}
+	return result
+}
+
+func toReadResult(readOperationResult computervision.ReadOperationResult) ReadResult {
+	result := ReadResult{Status: string(readOperationResult.Status)}
+	if readOperationResult.RecognitionResults == nil {
+		return result
+	}
+	for _, recResult := range *readOperationResult.RecognitionResults {
+		page := ReadPage{
+			Page:                 int32Value(recResult.Page),
+			ClockwiseOrientation: float64Value(recResult.ClockwiseOrientation),
+			Width:                float64Value(recResult.Width),
+			Height:               float64Value(recResult.Height),
+			Unit:                 string(recResult.Unit),
+		}
+		if recResult.Lines != nil {
+			for _, line := range *recResult.Lines {
+				polygon := geometry.FromCoordinates(intSlice(line.BoundingBox))
+				readLine := ReadLine{
+					Text:      stringValue(line.Text),
+					Rectangle: polygon.Bounds(),
+					Polygon:   polygon,
+				}
+				if line.Words != nil {
+					for _, word := range *line.Words {
+						polygon := geometry.FromCoordinates(intSlice(word.BoundingBox))
+						readLine.Words = append(readLine.Words, ReadWord{
+							Text:       stringValue(word.Text),
+							Rectangle:  polygon.Bounds(),
+							Polygon:    polygon,
+							Confidence: string(word.Confidence),
+						})
+					}
+				}
+				page.Lines = append(page.Lines, readLine)
+			}
+		}
+		result.Pages = append(result.Pages, page)
+	}
+	return result
+}
+
+func toOCRResult(ocrResult computervision.OcrResult) OCRResult {
+	result := OCRResult{
+		Language:    stringValue(ocrResult.Language),
+		TextAngle:   float64Value(ocrResult.TextAngle),
+		Orientation: stringValue(ocrResult.Orientation),
+	}
+	if ocrResult.Regions == nil {
+		return result
+	}
+	for _, region := range *ocrResult.Regions {
+		ocrRegion := OCRRegion{Rectangle: parseRect(region.BoundingBox)}
+		if region.Lines != nil {
+			for _, line := range *region.Lines {
+				ocrLine := OCRLine{Rectangle: parseRect(line.BoundingBox)}
+				if line.Words != nil {
+					for _, word := range *line.Words {
+						ocrLine.Words = append(ocrLine.Words, OCRWord{
+							Text:      stringValue(word.Text),
+							Rectangle: parseRect(word.BoundingBox),
+						})
+					}
+				}
+				ocrRegion.Lines = append(ocrRegion.Lines, ocrLine)
+			}
+		}
+		result.Regions = append(result.Regions, ocrRegion)
+	}
+	return result
+}
+
+// parseRect parses an OCR bounding box. A missing or malformed one, which
+// the service never returns, is empty.
+func parseRect(boundingBox *string) geometry.Rect {
+	rect, err := geometry.ParseRect(stringValue(boundingBox))
+	if err != nil {
+		return geometry.Rect{}
+	}
+	return rect
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func float64Value(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+	return *f
+}
+
+func int32Value(i *int32) int {
+	if i == nil {
+		return 0
+	}
+	return int(*i)
+}
+
+func boolValue(b *bool) bool {
+	if b == nil {
+		return false
+	}
+	return *b
+}
+
+func intSlice(values *[]int32) []int {
+	if values == nil {
+		return nil
+	}
+	result := make([]int, len(*values))
+	for i, v := range *values {
+		result[i] = int(v)
+	}
+	return result
+}
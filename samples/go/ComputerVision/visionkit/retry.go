@@ -0,0 +1,172 @@
+package visionkit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// Defaults of RetryPolicy.
+const (
+	DefaultMaxAttempts = 4
+	DefaultMaxElapsed  = time.Minute
+)
+
+// DefaultRetryableStatus are the status codes retried by default: request
+// timeouts, rate limiting, and transient server errors.
+var DefaultRetryableStatus = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryError is returned when a call still fails after being retried, or
+// when a Retry-After would take it past RetryPolicy.MaxElapsed.
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return "giving up after " + strconv.Itoa(e.Attempts) + " attempts: " + e.Err.Error()
+}
+
+// Unwrap returns the error of the last attempt.
+func (e *RetryError) Unwrap() error { return e.Err }
+
+// RetryPolicy retries the calls that fail with a retryable status code or a
+// network error. The zero value uses the defaults listed on each field.
+//
+// Every attempt opens the ImageSource again, so local files and in-memory
+// images are sent in full each time. Sources made with Reader or Stdin can
+// only be read once and are not retried; wrap their data with Bytes to make
+// them retryable.
+type RetryPolicy struct {
+	// MaxAttempts caps the attempts of a call, the first one included.
+	// Defaults to DefaultMaxAttempts; 1 turns retries off.
+	MaxAttempts int
+	// MaxElapsed caps the time spent on a call, waits included. A
+	// Retry-After that would go past it ends the retries early. Defaults to
+	// DefaultMaxElapsed; a negative value leaves only the context deadline.
+	MaxElapsed time.Duration
+	// Backoff sets the delays between attempts. A Retry-After header on the
+	// response takes precedence over it.
+	Backoff Backoff
+	// RetryableStatus are the status codes to retry. Defaults to
+	// DefaultRetryableStatus.
+	RetryableStatus []int
+	// OnRetry, if set, is called before each wait with the error of the
+	// failed attempt, counting from 1.
+	OnRetry func(attempt int, err error, wait time.Duration)
+}
+
+// Do calls call until it succeeds, fails with an error that is not
+// retryable, or the policy runs out of attempts or time.
+func (p RetryPolicy) Do(ctx context.Context, call func() error) error {
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	maxElapsed := p.MaxElapsed
+	if maxElapsed == 0 {
+		maxElapsed = DefaultMaxElapsed
+	}
+	start := time.Now()
+
+	for attempt := 1; ; attempt++ {
+		err := call()
+		if err == nil || ctx.Err() != nil || !p.retryable(err) {
+			return err
+		}
+		if attempt >= maxAttempts {
+			if attempt == 1 {
+				return err
+			}
+			return &RetryError{Attempts: attempt, Err: err}
+		}
+
+		wait, ok := retryAfter(errorResponse(err))
+		if !ok {
+			wait = p.Backoff.Delay(attempt - 1)
+		}
+		if maxElapsed > 0 && time.Since(start)+wait > maxElapsed {
+			return &RetryError{Attempts: attempt, Err: err}
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, err, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable tells whether err is worth another attempt.
+func (p RetryPolicy) retryable(err error) bool {
+	if resp := errorResponse(err); resp != nil {
+		codes := p.RetryableStatus
+		if codes == nil {
+			codes = DefaultRetryableStatus
+		}
+		for _, code := range codes {
+			if resp.StatusCode == code {
+				return true
+			}
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// errorResponse returns the HTTP response carried by an SDK error, if any.
+func errorResponse(err error) *http.Response {
+	var detailed autorest.DetailedError
+	if errors.As(err, &detailed) {
+		return detailed.Response
+	}
+	var detailedPtr *autorest.DetailedError
+	if errors.As(err, &detailedPtr) {
+		return detailedPtr.Response
+	}
+	return nil
+}
+
+// call sends an image with the client's retry policy: remote runs for
+// images with a URL, and local runs with a freshly opened stream for all the
+// others, once per attempt. A ReaderSource cannot be opened twice, so it
+// gets a single attempt.
+func (c *Client) call(ctx context.Context, image ImageSource, remote func(computervision.ImageURL) error, local func(io.ReadCloser) error) error {
+	policy := c.Retry
+	if _, oneShot := image.(ReaderSource); oneShot {
+		policy.MaxAttempts = 1
+	}
+	return policy.Do(ctx, func() error {
+		if imageURL, ok := image.RemoteURL(); ok {
+			return remote(remoteImage(imageURL))
+		}
+
+		localImage, err := image.Open()
+		if err != nil {
+			return err
+		}
+		defer localImage.Close()
+
+		return local(localImage)
+	})
+}
@@ -0,0 +1,170 @@
+package visionkit_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+)
+
+var image = visionkit.URL("https://example.com/dog.jpg")
+
+// fastRetries retries at once, so that the tests do not wait.
+var fastRetries = visionkit.Backoff{Initial: time.Millisecond, Max: time.Millisecond, Jitter: -1}
+
+// statusCode returns the HTTP status of a failed call, or 200 for nil.
+func statusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	var detailed autorest.DetailedError
+	if errors.As(err, &detailed) {
+		if code, ok := detailed.StatusCode.(int); ok {
+			return code
+		}
+	}
+	return 0
+}
+
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		queued       []cvtest.Response
+		maxAttempts  int
+		wantRequests int
+		wantAttempts int // of the RetryError; 0 for none
+		wantStatus   int
+		wantWaits    []time.Duration
+	}{
+		{
+			name:         "recovers",
+			queued:       []cvtest.Response{cvtest.ServerError(http.StatusInternalServerError), cvtest.ServerError(http.StatusServiceUnavailable)},
+			wantRequests: 3,
+			wantStatus:   http.StatusOK,
+			wantWaits:    []time.Duration{time.Millisecond, time.Millisecond},
+		},
+		{
+			name:         "gives up",
+			queued:       []cvtest.Response{cvtest.ServerError(http.StatusBadGateway), cvtest.ServerError(http.StatusBadGateway), cvtest.ServerError(http.StatusBadGateway)},
+			maxAttempts:  2,
+			wantRequests: 2,
+			wantAttempts: 2,
+			wantStatus:   http.StatusBadGateway,
+			wantWaits:    []time.Duration{time.Millisecond},
+		},
+		{
+			name:         "not retryable",
+			queued:       []cvtest.Response{cvtest.ServerError(http.StatusBadRequest)},
+			wantRequests: 1,
+			wantStatus:   http.StatusBadRequest,
+		},
+		{
+			name:         "retry after",
+			queued:       []cvtest.Response{cvtest.RateLimited(0)},
+			wantRequests: 2,
+			wantStatus:   http.StatusOK,
+			wantWaits:    []time.Duration{0},
+		},
+		{
+			name:         "retry after past max elapsed",
+			queued:       []cvtest.Response{cvtest.RateLimited(time.Hour)},
+			wantRequests: 1,
+			wantAttempts: 1,
+			wantStatus:   http.StatusTooManyRequests,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := cvtest.NewServer()
+			defer server.Close()
+			server.Enqueue(cvtest.RouteTag, test.queued...)
+			client := server.Client()
+			var waits []time.Duration
+			client.Retry = visionkit.RetryPolicy{
+				MaxAttempts: test.maxAttempts,
+				Backoff:     fastRetries,
+				OnRetry:     func(attempt int, err error, wait time.Duration) { waits = append(waits, wait) },
+			}
+
+			_, err := client.Tag(context.Background(), image)
+			if got := statusCode(err); got != test.wantStatus {
+				t.Errorf("status = %v (%v), want %v", got, err, test.wantStatus)
+			}
+			var retryErr *visionkit.RetryError
+			if errors.As(err, &retryErr) != (test.wantAttempts > 0) || (retryErr != nil && retryErr.Attempts != test.wantAttempts) {
+				t.Errorf("error = %v, want a RetryError after %v attempts", err, test.wantAttempts)
+			}
+			if requests := server.RequestCount(cvtest.RouteTag); requests != test.wantRequests {
+				t.Errorf("requests = %v, want %v", requests, test.wantRequests)
+			}
+			if len(waits) != len(test.wantWaits) {
+				t.Fatalf("waits = %v, want %v", waits, test.wantWaits)
+			}
+			for i := range waits {
+				if waits[i] != test.wantWaits[i] {
+					t.Errorf("waits = %v, want %v", waits, test.wantWaits)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryReopensLocalImage(t *testing.T) {
+	data := []byte("not really a jpeg, but the fake service does not mind")
+	tests := []struct {
+		name         string
+		image        visionkit.ImageSource
+		wantRequests int
+	}{
+		{name: "bytes", image: visionkit.Bytes("dog.jpg", data), wantRequests: 3},
+		{name: "reader", image: visionkit.Reader("dog.jpg", bytes.NewReader(data)), wantRequests: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := cvtest.NewServer()
+			defer server.Close()
+			server.Enqueue(cvtest.RouteTag, cvtest.ServerError(http.StatusInternalServerError), cvtest.ServerError(http.StatusInternalServerError))
+			client := server.Client()
+			client.Retry = visionkit.RetryPolicy{Backoff: fastRetries}
+
+			client.Tag(context.Background(), test.image)
+			requests := server.Requests()
+			if len(requests) != test.wantRequests {
+				t.Fatalf("requests = %v, want %v", len(requests), test.wantRequests)
+			}
+			for i, request := range requests {
+				if !bytes.Equal(request.Body, data) {
+					t.Errorf("request %v sent %q, want the whole image", i+1, request.Body)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	server.Handle(cvtest.RouteTag, cvtest.RateLimited(30*time.Second))
+	client := server.Client()
+	client.Retry = visionkit.RetryPolicy{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.Tag(ctx, image)
+	if got := statusCode(err); got != http.StatusTooManyRequests {
+		t.Errorf("error = %v, want the rate limit of the last attempt", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Tag took %v, want it to stop with the context", elapsed)
+	}
+	if requests := server.RequestCount(cvtest.RouteTag); requests != 1 {
+		t.Errorf("requests = %v, want 1", requests)
+	}
+}
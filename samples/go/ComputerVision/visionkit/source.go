@@ -0,0 +1,134 @@
+package visionkit
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrSourceConsumed is returned when a source backed by a one-shot reader is
+// opened a second time.
+var ErrSourceConsumed = errors.New("visionkit: image source has already been read")
+
+// ImageSource is an image that can be sent to the Computer Vision service.
+// A source either has a URL the service can fetch by itself, in which case
+// the URL flavour of an SDK method is used (for example AnalyzeImage), or it
+// is opened and uploaded with the ...InStream flavour (AnalyzeImageInStream).
+//
+// New kinds of sources, such as object stores, only need to implement this
+// interface to work with every operation in the package.
+type ImageSource interface {
+	// Name identifies the image in results and error messages.
+	Name() string
+	// RemoteURL returns the URL of the image and true if the service should
+	// fetch the image itself.
+	RemoteURL() (string, bool)
+	// Open returns a stream of the image bytes. The caller closes it.
+	Open() (io.ReadCloser, error)
+}
+
+// FileSource is an image on the local file system.
+type FileSource struct {
+	Path string
+}
+
+// File returns a source for the local image at path.
+func File(path string) FileSource {
+	return FileSource{Path: path}
+}
+
+func (s FileSource) Name() string              { return s.Path }
+func (s FileSource) RemoteURL() (string, bool) { return "", false }
+func (s FileSource) Open() (io.ReadCloser, error) {
+	return os.Open(s.Path)
+}
+
+// URLSource is an image the service downloads from a URL.
+type URLSource struct {
+	URL string
+}
+
+// URL returns a source for the remote image at url.
+func URL(url string) URLSource {
+	return URLSource{URL: url}
+}
+
+func (s URLSource) Name() string              { return s.URL }
+func (s URLSource) RemoteURL() (string, bool) { return s.URL, true }
+
+// Open is not supported for URL sources because the service fetches them.
+func (s URLSource) Open() (io.ReadCloser, error) {
+	return nil, errors.New("visionkit: " + s.URL + " is fetched by the service and cannot be opened locally")
+}
+
+// BytesSource is an image held in memory.
+type BytesSource struct {
+	Label string
+	Data  []byte
+}
+
+// Bytes returns a source for the in-memory image data. The name is only used
+// to identify the image.
+func Bytes(name string, data []byte) BytesSource {
+	return BytesSource{Label: name, Data: data}
+}
+
+func (s BytesSource) Name() string              { return s.Label }
+func (s BytesSource) RemoteURL() (string, bool) { return "", false }
+func (s BytesSource) Open() (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(s.Data)), nil
+}
+
+// ReaderSource is an image read from an io.Reader, such as standard input.
+// The reader can only be consumed once.
+type ReaderSource struct {
+	label  string
+	reader io.Reader
+	once   *sync.Once
+}
+
+// Reader returns a source that streams the image from r. If r is also an
+// io.Closer it is closed by the operation that uploads it.
+func Reader(name string, r io.Reader) ReaderSource {
+	return ReaderSource{label: name, reader: r, once: new(sync.Once)}
+}
+
+// Stdin returns a source that reads the image from standard input.
+func Stdin() ReaderSource {
+	return Reader("stdin", os.Stdin)
+}
+
+func (s ReaderSource) Name() string              { return s.label }
+func (s ReaderSource) RemoteURL() (string, bool) { return "", false }
+func (s ReaderSource) Open() (io.ReadCloser, error) {
+	var image io.ReadCloser
+	s.once.Do(func() {
+		if rc, ok := s.reader.(io.ReadCloser); ok {
+			image = rc
+		} else {
+			image = ioutil.NopCloser(s.reader)
+		}
+	})
+	if image == nil {
+		return nil, ErrSourceConsumed
+	}
+	return image, nil
+}
+
+// ParseSource turns a command-line style argument into a source: "-" reads
+// standard input, http:// and https:// arguments are remote images, and
+// anything else is a local file path.
+func ParseSource(arg string) ImageSource {
+	switch {
+	case arg == "-":
+		return Stdin()
+	case strings.HasPrefix(arg, "http://"), strings.HasPrefix(arg, "https://"):
+		return URL(arg)
+	default:
+		return File(arg)
+	}
+}
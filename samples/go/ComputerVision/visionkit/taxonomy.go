@@ -0,0 +1,113 @@
+package visionkit
+
+import (
+	"sort"
+	"strings"
+)
+
+// Path returns the name of the object followed by the names of its parents,
+// from the most specific category to the broadest, for example
+// ["Labrador", "dog", "mammal", "animal"].
+func (o DetectedObject) Path() []string {
+	path := []string{o.Name}
+	for _, parent := range o.Parents {
+		path = append(path, parent.Name)
+	}
+	return path
+}
+
+// Is reports whether the object is a name, or belongs to the category name
+// somewhere in its taxonomy, and returns the confidence of the service at
+// that level. Names are compared without regard to case.
+func (o DetectedObject) Is(name string) (float64, bool) {
+	if strings.EqualFold(o.Name, name) {
+		return o.Confidence, true
+	}
+	for _, parent := range o.Parents {
+		if strings.EqualFold(parent.Name, name) {
+			return parent.Confidence, true
+		}
+	}
+	return 0, false
+}
+
+// ObjectCount is the number of detected objects that are, or belong to, one
+// category of the object taxonomy.
+type ObjectCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+	// Confidence is the mean confidence of the objects at this level.
+	Confidence float64 `json:"confidence"`
+	// MaxConfidence is the confidence of the surest of the objects.
+	MaxConfidence float64 `json:"maxConfidence"`
+	// Expected is the sum of the confidences, the number of objects of the
+	// category expected given how sure the service is of each.
+	Expected float64 `json:"expected"`
+}
+
+// CountObjects counts the objects that are name or belong to it, such as
+// every dog, cat, and bird for "animal".
+func CountObjects(objects []DetectedObject, name string) ObjectCount {
+	count := ObjectCount{Name: name}
+	for _, object := range objects {
+		if confidence, ok := object.Is(name); ok {
+			count.add(confidence)
+		}
+	}
+	count.finish()
+	return count
+}
+
+// RollUp counts the objects at every level of the taxonomy: each object is
+// counted under its own name and under each of its parents. The counts are
+// sorted by number of objects, most first, then by name. A name is counted
+// once per object even if it appears twice in its path.
+func RollUp(objects []DetectedObject) []ObjectCount {
+	counts := map[string]*ObjectCount{}
+	var names []string
+	for _, object := range objects {
+		seen := map[string]bool{}
+		levels := append([]ObjectParent{{Name: object.Name, Confidence: object.Confidence}}, object.Parents...)
+		for _, level := range levels {
+			key := strings.ToLower(level.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			count, ok := counts[key]
+			if !ok {
+				count = &ObjectCount{Name: level.Name}
+				counts[key] = count
+				names = append(names, key)
+			}
+			count.add(level.Confidence)
+		}
+	}
+
+	rolledUp := []ObjectCount{}
+	for _, key := range names {
+		counts[key].finish()
+		rolledUp = append(rolledUp, *counts[key])
+	}
+	sort.SliceStable(rolledUp, func(i, j int) bool {
+		if rolledUp[i].Count != rolledUp[j].Count {
+			return rolledUp[i].Count > rolledUp[j].Count
+		}
+		return rolledUp[i].Name < rolledUp[j].Name
+	})
+	return rolledUp
+}
+
+func (c *ObjectCount) add(confidence float64) {
+	c.Count++
+	c.Expected += confidence
+	if confidence > c.MaxConfidence {
+		c.MaxConfidence = confidence
+	}
+}
+
+func (c *ObjectCount) finish() {
+	if c.Count > 0 {
+		c.Confidence = c.Expected / float64(c.Count)
+	}
+}
@@ -0,0 +1,101 @@
+package visionkit_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+)
+
+// Confidences are powers of two so that sums and means are exact.
+var (
+	labrador = visionkit.DetectedObject{Name: "Labrador", Confidence: 0.5, Parents: []visionkit.ObjectParent{
+		{Name: "dog", Confidence: 0.75}, {Name: "mammal", Confidence: 0.875}, {Name: "animal", Confidence: 1},
+	}}
+	cat = visionkit.DetectedObject{Name: "cat", Confidence: 0.25, Parents: []visionkit.ObjectParent{
+		{Name: "Mammal", Confidence: 0.5}, {Name: "animal", Confidence: 0.5},
+	}}
+	// An object whose own name is repeated as its parent counts once.
+	person = visionkit.DetectedObject{Name: "person", Confidence: 0.5, Parents: []visionkit.ObjectParent{{Name: "Person", Confidence: 0.75}}}
+)
+
+func TestPathParsed(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	objects, err := client.DetectObjects(context.Background(), image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"person"}, {"dog", "mammal", "animal"}}
+	if len(objects) != len(want) {
+		t.Fatalf("objects = %+v, want %v", objects, len(want))
+	}
+	for i, object := range objects {
+		if got := object.Path(); !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("Path of %v = %v, want %v", object.Name, got, want[i])
+		}
+	}
+	if confidence := objects[1].Parents[1].Confidence; confidence != 0.92 {
+		t.Errorf("animal confidence = %v, want 0.92", confidence)
+	}
+}
+
+func TestIs(t *testing.T) {
+	tests := []struct {
+		name           string
+		wantConfidence float64
+		wantOK         bool
+	}{
+		{"Labrador", 0.5, true},
+		{"DOG", 0.75, true},
+		{"animal", 1, true},
+		{"cat", 0, false},
+	}
+	for _, test := range tests {
+		confidence, ok := labrador.Is(test.name)
+		if confidence != test.wantConfidence || ok != test.wantOK {
+			t.Errorf("Is(%q) = %v, %v, want %v, %v", test.name, confidence, ok, test.wantConfidence, test.wantOK)
+		}
+	}
+}
+
+func TestCountObjects(t *testing.T) {
+	objects := []visionkit.DetectedObject{labrador, cat, person}
+	tests := []struct {
+		name string
+		want visionkit.ObjectCount
+	}{
+		{"mammal", visionkit.ObjectCount{Name: "mammal", Count: 2, Confidence: 0.6875, MaxConfidence: 0.875, Expected: 1.375}},
+		{"cat", visionkit.ObjectCount{Name: "cat", Count: 1, Confidence: 0.25, MaxConfidence: 0.25, Expected: 0.25}},
+		{"bird", visionkit.ObjectCount{Name: "bird"}},
+	}
+	for _, test := range tests {
+		if got := visionkit.CountObjects(objects, test.name); got != test.want {
+			t.Errorf("CountObjects(%q) = %+v, want %+v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestRollUp(t *testing.T) {
+	got := visionkit.RollUp([]visionkit.DetectedObject{labrador, cat, person})
+	// Names keep the case they were first seen with.
+	want := []visionkit.ObjectCount{
+		{Name: "animal", Count: 2, Confidence: 0.75, MaxConfidence: 1, Expected: 1.5},
+		{Name: "mammal", Count: 2, Confidence: 0.6875, MaxConfidence: 0.875, Expected: 1.375},
+		{Name: "Labrador", Count: 1, Confidence: 0.5, MaxConfidence: 0.5, Expected: 0.5},
+		{Name: "cat", Count: 1, Confidence: 0.25, MaxConfidence: 0.25, Expected: 0.25},
+		{Name: "dog", Count: 1, Confidence: 0.75, MaxConfidence: 0.75, Expected: 0.75},
+		{Name: "person", Count: 1, Confidence: 0.5, MaxConfidence: 0.5, Expected: 0.5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RollUp = %+v, want %+v", got, want)
+	}
+
+	if got := visionkit.RollUp(nil); got == nil || len(got) != 0 {
+		t.Errorf("RollUp(nil) = %#v, want an empty list", got)
+	}
+}
@@ -0,0 +1,78 @@
+package tile
+
+import (
+	"sort"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+)
+
+// Detection is a box found in one tile, in the coordinates of the whole
+// image, as seen by Suppress.
+type Detection struct {
+	// Class is what was found, such as the name of an object. Only
+	// detections of the same class are duplicates.
+	Class string
+	// Confidence ranks the duplicates; the highest is kept.
+	Confidence float64
+	Rect       geometry.Rect
+	// Cut is set when the box touches a side of its tile that is inside
+	// the image, so the thing found may go on in the next tile.
+	Cut bool
+	// Index identifies the detection for the caller.
+	Index int
+	// Duplicates are the Index values of the detections merged into this
+	// one, set by Suppress.
+	Duplicates []int
+}
+
+// Suppress removes the duplicates of the detections found where tiles
+// overlap, with greedy non-max suppression. Detections that are not cut are
+// kept first, then the ones with the highest confidence. A detection is a
+// duplicate of a kept one of the same class when their IoU is at least
+// threshold or, when either is cut, when that much of the smaller one lies
+// inside the other. A kept detection grows to cover the duplicates when
+// either is cut, which joins the parts of a thing split by a seam.
+func Suppress(detections []Detection, threshold float64) []Detection {
+	ordered := make([]Detection, len(detections))
+	copy(ordered, detections)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Cut != ordered[j].Cut {
+			return !ordered[i].Cut
+		}
+		return ordered[i].Confidence > ordered[j].Confidence
+	})
+
+	var kept []Detection
+	for _, detection := range ordered {
+		duplicate := false
+		for k := range kept {
+			if kept[k].Class != detection.Class || !duplicates(kept[k], detection, threshold) {
+				continue
+			}
+			if kept[k].Cut || detection.Cut {
+				kept[k].Rect = kept[k].Rect.Union(detection.Rect)
+			}
+			kept[k].Duplicates = append(kept[k].Duplicates, detection.Index)
+			duplicate = true
+			break
+		}
+		if !duplicate {
+			kept = append(kept, detection)
+		}
+	}
+	return kept
+}
+
+func duplicates(a, b Detection, threshold float64) bool {
+	if geometry.IoU(a.Rect, b.Rect) >= threshold {
+		return true
+	}
+	if !a.Cut && !b.Cut {
+		return false
+	}
+	smaller := a.Rect.Area()
+	if area := b.Rect.Area(); area < smaller {
+		smaller = area
+	}
+	return smaller > 0 && a.Rect.Intersect(b.Rect).Area()/smaller >= threshold
+}
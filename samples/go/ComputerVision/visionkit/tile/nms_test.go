@@ -0,0 +1,65 @@
+package tile_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/tile"
+)
+
+func TestSuppress(t *testing.T) {
+	box := geometry.Rect{X: 0, Y: 0, W: 100, H: 40}
+	tests := []struct {
+		name       string
+		detections []tile.Detection
+		want       []tile.Detection
+	}{
+		{
+			name: "highest confidence kept",
+			detections: []tile.Detection{
+				{Class: "dog", Confidence: 0.6, Rect: box, Index: 0},
+				{Class: "dog", Confidence: 0.9, Rect: box.Translate(5, 0), Index: 1},
+			},
+			want: []tile.Detection{{Class: "dog", Confidence: 0.9, Rect: box.Translate(5, 0), Index: 1, Duplicates: []int{0}}},
+		},
+		{
+			name: "other class kept",
+			detections: []tile.Detection{
+				{Class: "dog", Confidence: 0.6, Rect: box, Index: 0},
+				{Class: "cat", Confidence: 0.9, Rect: box, Index: 1},
+			},
+			want: []tile.Detection{
+				{Class: "cat", Confidence: 0.9, Rect: box, Index: 1},
+				{Class: "dog", Confidence: 0.6, Rect: box, Index: 0},
+			},
+		},
+		{
+			name: "little overlap kept",
+			detections: []tile.Detection{
+				{Class: "dog", Confidence: 0.9, Rect: box, Index: 0},
+				{Class: "dog", Confidence: 0.6, Rect: box.Translate(80, 0), Index: 1},
+			},
+			want: []tile.Detection{
+				{Class: "dog", Confidence: 0.9, Rect: box, Index: 0},
+				{Class: "dog", Confidence: 0.6, Rect: box.Translate(80, 0), Index: 1},
+			},
+		},
+		{
+			name: "part cut by a seam joined",
+			detections: []tile.Detection{
+				{Class: "dog", Confidence: 0.9, Rect: geometry.Rect{X: 80, Y: 0, W: 30, H: 40}, Cut: true, Index: 0},
+				{Class: "dog", Confidence: 0.5, Rect: box, Index: 1},
+			},
+			want: []tile.Detection{{Class: "dog", Confidence: 0.5, Rect: geometry.Rect{X: 0, Y: 0, W: 110, H: 40}, Index: 1, Duplicates: []int{0}}},
+		},
+		{name: "nothing"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := tile.Suppress(test.detections, 0.5); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Suppress = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,425 @@
+// Package tile analyzes very large images, such as high-resolution scans and
+// panoramas, in overlapping tiles. Sent whole, such an image is downscaled
+// to the size limits of the service and its small objects and text are lost;
+// each tile keeps the full resolution. The results of the tiles are moved
+// back to the coordinates of the whole image, and the duplicates found where
+// tiles overlap are merged with non-max suppression (see Suppress).
+//
+//	objects, err := tile.DetectObjects(ctx, client, visionkit.File("scan.tif"), tile.Options{})
+//
+// Every tile is a separate call, so a 4000x3000 image in the default tiles
+// takes 20 calls, and one more for the whole image.
+package tile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/preprocess"
+)
+
+// ErrRemote is returned for images the service fetches from a URL, whose
+// pixels are needed to cut the tiles.
+var ErrRemote = errors.New("tile: remote images must be downloaded before they are tiled")
+
+// Defaults of Options.
+const (
+	DefaultSize    = 1024
+	DefaultOverlap = 200
+	DefaultIoU     = 0.5
+)
+
+// seamMargin is how close to a side of its tile, in pixels, a box must be to
+// count as cut.
+const seamMargin = 2
+
+// Options change how an image is tiled. The zero value uses the defaults
+// listed on each field.
+type Options struct {
+	// Size is the side of the tiles in pixels. Defaults to DefaultSize.
+	Size int
+	// Overlap is the number of pixels neighbouring tiles share. It must be
+	// less than half of Size. Defaults to DefaultOverlap.
+	Overlap int
+	// IoU is the threshold of Suppress. Defaults to DefaultIoU.
+	IoU float64
+	// Concurrency is the number of tiles analyzed at once. Defaults to
+	// visionkit.DefaultConcurrency.
+	Concurrency int
+	// TilesOnly skips the analysis of the whole image, which finds the
+	// objects and lines of text larger than a tile in one piece. The whole
+	// image is analyzed on a best-effort basis: if the service rejects it,
+	// for example because it is over the upload limits, the results of the
+	// tiles are used alone.
+	TilesOnly bool
+}
+
+// Grid returns the tiles that cover an image of the given size. The tiles
+// are size pixels square, or the size of the image when it is smaller, and
+// neighbouring tiles share overlap pixels or more; the last tile of each row
+// and column is aligned with the edge of the image.
+func Grid(width, height, size, overlap int) []image.Rectangle {
+	var tiles []image.Rectangle
+	for _, y := range starts(height, size, overlap) {
+		for _, x := range starts(width, size, overlap) {
+			tiles = append(tiles, image.Rect(x, y, x+min(size, width), y+min(size, height)))
+		}
+	}
+	return tiles
+}
+
+// starts returns the start of each tile along one side.
+func starts(length, size, overlap int) []int {
+	if length <= size {
+		return []int{0}
+	}
+	var positions []int
+	for position := 0; position+size < length; position += size - overlap {
+		positions = append(positions, position)
+	}
+	return append(positions, length-size)
+}
+
+// part is a tile, or the whole image, ready to be sent.
+type part struct {
+	rect   image.Rectangle
+	bounds image.Rectangle
+	image  visionkit.ImageSource
+	whole  bool
+}
+
+// cut reports whether rect touches a side of the part that is inside the
+// image.
+func (p part) cut(rect geometry.Rect) bool {
+	if p.whole {
+		return false
+	}
+	min, max := rect.Min(), rect.Max()
+	return (p.rect.Min.X > p.bounds.Min.X && min.X <= float64(p.rect.Min.X+seamMargin)) ||
+		(p.rect.Min.Y > p.bounds.Min.Y && min.Y <= float64(p.rect.Min.Y+seamMargin)) ||
+		(p.rect.Max.X < p.bounds.Max.X && max.X >= float64(p.rect.Max.X-seamMargin)) ||
+		(p.rect.Max.Y < p.bounds.Max.Y && max.Y >= float64(p.rect.Max.Y-seamMargin))
+}
+
+// offset moves a rectangle found in the part into the whole image.
+func (p part) offset(rect geometry.Rect) geometry.Rect {
+	return rect.Translate(float64(p.rect.Min.X), float64(p.rect.Min.Y))
+}
+
+// split reads and decodes a local image, turns it upright by its EXIF
+// orientation, and cuts it into tiles. The whole image comes first unless
+// options.TilesOnly is set. An image that fits in one tile is only sent
+// whole.
+func split(source visionkit.ImageSource, options Options) ([]part, error) {
+	if _, remote := source.RemoteURL(); remote {
+		return nil, ErrRemote
+	}
+	size, overlap := options.size(), options.overlap()
+	if size < preprocess.MinDimension {
+		return nil, fmt.Errorf("tile: the tile size of %v pixels is less than the %v the service accepts", size, preprocess.MinDimension)
+	}
+	if overlap*2 >= size {
+		return nil, fmt.Errorf("tile: the overlap of %v pixels must be less than half of the tile size of %v", overlap, size)
+	}
+
+	reader, err := source.Open()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return nil, err
+	}
+	whole := part{image: visionkit.Bytes(source.Name(), data), whole: true}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("tile: decoding %v: %w", source.Name(), err)
+	}
+	format := preprocess.Sniff(data)
+	img := decoded
+	// The tiles are cut from the upright image, so the whole image is sent
+	// upright too, whether or not the client has a Preprocessor.
+	if orientation := preprocess.Orientation(data); orientation != preprocess.OrientationNormal {
+		img = preprocess.Orient(decoded, orientation)
+		if data, err = encode(img, format); err != nil {
+			return nil, err
+		}
+		whole.image = visionkit.Bytes(source.Name(), data)
+	}
+	bounds := img.Bounds()
+	whole.rect, whole.bounds = bounds, bounds
+
+	rects := Grid(bounds.Dx(), bounds.Dy(), size, overlap)
+	if len(rects) == 1 {
+		return []part{whole}, nil
+	}
+	var parts []part
+	if !options.TilesOnly {
+		parts = append(parts, whole)
+	}
+	for _, rect := range rects {
+		rect = rect.Add(bounds.Min)
+		encoded, err := encode(subImage(img, rect), format)
+		if err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("%v [tile %v,%v]", source.Name(), rect.Min.X-bounds.Min.X, rect.Min.Y-bounds.Min.Y)
+		parts = append(parts, part{rect: rect.Sub(bounds.Min), bounds: image.Rect(0, 0, bounds.Dx(), bounds.Dy()), image: visionkit.Bytes(name, encoded)})
+	}
+	return parts, nil
+}
+
+// subImage returns the part of img inside rect.
+func subImage(img image.Image, rect image.Rectangle) image.Image {
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+	return img
+}
+
+// encode writes a tile as a PNG if the image was a PNG or a GIF, which may
+// have transparent parts, and as a JPEG otherwise.
+func encode(img image.Image, format string) ([]byte, error) {
+	var encoded bytes.Buffer
+	var err error
+	if format == preprocess.FormatPNG || format == preprocess.FormatGIF {
+		err = png.Encode(&encoded, img)
+	} else {
+		err = jpeg.Encode(&encoded, img, &jpeg.Options{Quality: preprocess.JPEGQuality})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tile: encoding a tile: %w", err)
+	}
+	return encoded.Bytes(), nil
+}
+
+// each runs analyze on every part with at most concurrency calls at once.
+// The first error of a tile cancels the calls not yet done and is returned.
+// An error of the whole image is dropped, leaving its results empty: a scan
+// too large to be sent whole is what tiling is for.
+func each(ctx context.Context, parts []part, concurrency int, analyze func(ctx context.Context, i int, p part) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var first error
+	var once sync.Once
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, concurrency)
+	for i, p := range parts {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, p part) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if err := analyze(ctx, i, p); err != nil && !p.whole {
+				once.Do(func() {
+					first = err
+					cancel()
+				})
+			}
+		}(i, p)
+	}
+	wg.Wait()
+	return first
+}
+
+// DetectObjects detects the objects in every tile of a local image and in
+// the whole image, and merges them.
+func DetectObjects(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, options Options) ([]visionkit.DetectedObject, error) {
+	parts, err := split(image, options)
+	if err != nil {
+		return nil, &visionkit.Error{Op: "objects", Image: image.Name(), Err: err}
+	}
+	if len(parts) == 1 {
+		return client.DetectObjects(ctx, parts[0].image)
+	}
+
+	found := make([][]visionkit.DetectedObject, len(parts))
+	err = each(ctx, parts, options.concurrency(), func(ctx context.Context, i int, p part) (err error) {
+		found[i], err = client.DetectObjects(ctx, p.image)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var objects []visionkit.DetectedObject
+	var detections []Detection
+	for i, p := range parts {
+		for _, object := range found[i] {
+			object.Rectangle = p.offset(object.Rectangle)
+			rect := object.Rectangle
+			detections = append(detections, Detection{Class: object.Name, Confidence: object.Confidence, Rect: rect, Cut: p.cut(rect), Index: len(objects)})
+			objects = append(objects, object)
+		}
+	}
+
+	merged := []visionkit.DetectedObject{}
+	for _, detection := range Suppress(detections, options.iou()) {
+		object := objects[detection.Index]
+		object.Rectangle = detection.Rect
+		merged = append(merged, object)
+	}
+	return merged, nil
+}
+
+// OCR recognizes the text in every tile of a local image and in the whole
+// image, and merges the lines. Of the lines found twice, the one not cut by
+// a seam and with the most characters is kept, and the words of a line cut
+// by a seam are joined with those of its other part. The language and the
+// orientation are those of the whole image, or of the first tile with text.
+func OCR(ctx context.Context, client *visionkit.Client, image visionkit.ImageSource, language computervision.OcrLanguages, options Options) (visionkit.OCRResult, error) {
+	parts, err := split(image, options)
+	if err != nil {
+		return visionkit.OCRResult{}, &visionkit.Error{Op: "ocr", Image: image.Name(), Err: err}
+	}
+	if len(parts) == 1 {
+		return client.OCR(ctx, parts[0].image, language)
+	}
+
+	found := make([]visionkit.OCRResult, len(parts))
+	err = each(ctx, parts, options.concurrency(), func(ctx context.Context, i int, p part) (err error) {
+		found[i], err = client.OCR(ctx, p.image, language)
+		return err
+	})
+	if err != nil {
+		return visionkit.OCRResult{}, err
+	}
+
+	// Every line, moved into the whole image, with the region it came from.
+	var lines []visionkit.OCRLine
+	var regionOf []int
+	var regions []visionkit.OCRRegion
+	var detections []Detection
+	bounds := parts[0].bounds
+	result := visionkit.OCRResult{Width: bounds.Dx(), Height: bounds.Dy()}
+	for i, p := range parts {
+		if len(found[i].Regions) > 0 && result.Language == "" {
+			result.Language, result.TextAngle, result.Orientation = found[i].Language, found[i].TextAngle, found[i].Orientation
+		}
+		for _, region := range found[i].Regions {
+			for _, line := range region.Lines {
+				line.Rectangle = p.offset(line.Rectangle)
+				line.Words = append([]visionkit.OCRWord{}, line.Words...)
+				for w := range line.Words {
+					line.Words[w].Rectangle = p.offset(line.Words[w].Rectangle)
+				}
+				rect := line.Rectangle
+				detections = append(detections, Detection{Confidence: float64(len(line.Text())), Rect: rect, Cut: p.cut(rect), Index: len(lines)})
+				lines = append(lines, line)
+				regionOf = append(regionOf, len(regions))
+			}
+			regions = append(regions, visionkit.OCRRegion{})
+		}
+	}
+
+	for _, detection := range Suppress(detections, options.iou()) {
+		line := lines[detection.Index]
+		for _, duplicate := range detection.Duplicates {
+			line.Words = joinWords(line.Words, lines[duplicate].Words, options.iou())
+		}
+		line.Rectangle = detection.Rect
+		region := &regions[regionOf[detection.Index]]
+		region.Lines = append(region.Lines, line)
+	}
+	for _, region := range regions {
+		if len(region.Lines) == 0 {
+			continue
+		}
+		var bounds geometry.Rect
+		for _, line := range region.Lines {
+			bounds = bounds.Union(line.Rectangle)
+		}
+		region.Rectangle = bounds
+		sort.SliceStable(region.Lines, func(i, j int) bool {
+			return region.Lines[i].Rectangle.Y < region.Lines[j].Rectangle.Y
+		})
+		result.Regions = append(result.Regions, region)
+	}
+	sort.SliceStable(result.Regions, func(i, j int) bool {
+		a, b := result.Regions[i].Rectangle, result.Regions[j].Rectangle
+		if a.Y != b.Y {
+			return a.Y < b.Y
+		}
+		return a.X < b.X
+	})
+	return result, nil
+}
+
+// joinWords adds the words of other that are not already in words, which
+// overlap less than threshold with every one of them, and orders the words
+// from left to right.
+func joinWords(words, other []visionkit.OCRWord, threshold float64) []visionkit.OCRWord {
+	joined := append([]visionkit.OCRWord{}, words...)
+	for _, candidate := range other {
+		found := false
+		for _, word := range words {
+			intersection := word.Rectangle.Intersect(candidate.Rectangle).Area()
+			smaller := word.Rectangle.Area()
+			if area := candidate.Rectangle.Area(); area < smaller {
+				smaller = area
+			}
+			if smaller > 0 && intersection/smaller >= threshold {
+				found = true
+				break
+			}
+		}
+		if !found {
+			joined = append(joined, candidate)
+		}
+	}
+	sort.SliceStable(joined, func(i, j int) bool {
+		return joined[i].Rectangle.X < joined[j].Rectangle.X
+	})
+	return joined
+}
+
+func (o Options) size() int {
+	if o.Size > 0 {
+		return o.Size
+	}
+	return DefaultSize
+}
+
+func (o Options) overlap() int {
+	if o.Overlap > 0 {
+		return o.Overlap
+	}
+	return DefaultOverlap
+}
+
+func (o Options) iou() float64 {
+	if o.IoU > 0 {
+		return o.IoU
+	}
+	return DefaultIoU
+}
+
+func (o Options) concurrency() int {
+	if o.Concurrency > 0 {
+		return o.Concurrency
+	}
+	return visionkit.DefaultConcurrency
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
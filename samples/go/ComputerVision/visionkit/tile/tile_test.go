@@ -0,0 +1,177 @@
+package tile_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"reflect"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/cognitiveservices/v2.0/computervision"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/cvtest"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/geometry"
+	"github.com/LukeBayler/cogserv-working-repo-LukeBayler/samples/go/ComputerVision/visionkit/tile"
+)
+
+func TestGrid(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		size, overlap int
+		want          []image.Rectangle
+	}{
+		{name: "smaller than a tile", width: 800, height: 600, size: 1024, overlap: 200, want: []image.Rectangle{image.Rect(0, 0, 800, 600)}},
+		{
+			name: "one row", width: 2000, height: 600, size: 1024, overlap: 200,
+			want: []image.Rectangle{image.Rect(0, 0, 1024, 600), image.Rect(824, 0, 1848, 600), image.Rect(976, 0, 2000, 600)},
+		},
+		{
+			name: "exact fit", width: 200, height: 180, size: 100, overlap: 20,
+			want: []image.Rectangle{
+				image.Rect(0, 0, 100, 100), image.Rect(80, 0, 180, 100), image.Rect(100, 0, 200, 100),
+				image.Rect(0, 80, 100, 180), image.Rect(80, 80, 180, 180), image.Rect(100, 80, 200, 180),
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := tile.Grid(test.width, test.height, test.size, test.overlap)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Grid = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// blank returns a PNG image of the given size.
+func blank(t *testing.T, width, height int) visionkit.ImageSource {
+	t.Helper()
+	var data bytes.Buffer
+	if err := png.Encode(&data, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return visionkit.Bytes("scan.png", data.Bytes())
+}
+
+func TestDetectObjects(t *testing.T) {
+	tests := []struct {
+		name         string
+		image        visionkit.ImageSource
+		options      tile.Options
+		wantRequests int
+		wantObjects  int
+		wantErr      error
+	}{
+		// The fake service finds a person and a dog in every tile, so the
+		// ones of the first tile are the ones of the whole image, and the
+		// others are new.
+		{name: "tiled", image: blank(t, 2000, 600), wantRequests: 4, wantObjects: 6},
+		{name: "tiles only", image: blank(t, 2000, 600), options: tile.Options{TilesOnly: true}, wantRequests: 3, wantObjects: 6},
+		{name: "one tile", image: blank(t, 800, 600), wantRequests: 1, wantObjects: 2},
+		{name: "remote", image: visionkit.URL("https://example.com/scan.png"), wantErr: tile.ErrRemote},
+		{name: "overlap too large", image: blank(t, 2000, 600), options: tile.Options{Size: 100, Overlap: 50}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := cvtest.NewServer()
+			defer server.Close()
+			client := server.Client()
+
+			objects, err := tile.DetectObjects(context.Background(), client, test.image, test.options)
+			if test.wantRequests == 0 {
+				if err == nil || test.wantErr != nil && !errors.Is(err, test.wantErr) {
+					t.Errorf("error = %v, want %v", err, test.wantErr)
+				}
+				if requests := len(server.Requests()); requests != 0 {
+					t.Errorf("requests = %v, want none", requests)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if requests := server.RequestCount(cvtest.RouteDetect); requests != test.wantRequests {
+				t.Errorf("requests = %v, want %v", requests, test.wantRequests)
+			}
+			if len(objects) != test.wantObjects {
+				t.Errorf("objects = %v, want %v", objects, test.wantObjects)
+			}
+			person := geometry.Rect{X: 90, Y: 120, W: 230, H: 480}
+			if len(objects) == 0 || objects[0].Rectangle != person {
+				t.Errorf("objects = %v, want the person of the first tile first", objects)
+			}
+		})
+	}
+}
+
+func TestWholeImageRejected(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []cvtest.Response
+		wantErr   bool
+	}{
+		{name: "whole image", responses: []cvtest.Response{cvtest.ServerError(400)}},
+		{name: "tile", responses: []cvtest.Response{{Body: cvtest.DetectBody}, cvtest.ServerError(400)}, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := cvtest.NewServer()
+			defer server.Close()
+			client := server.Client()
+			// One call at a time, the whole image first.
+			server.Enqueue(cvtest.RouteDetect, test.responses...)
+
+			objects, err := tile.DetectObjects(context.Background(), client, blank(t, 2000, 600), tile.Options{Concurrency: 1})
+			if test.wantErr {
+				if err == nil {
+					t.Error("DetectObjects succeeded with a tile rejected")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if requests := server.RequestCount(cvtest.RouteDetect); requests != 4 {
+				t.Errorf("requests = %v, want the whole image and 3 tiles", requests)
+			}
+			if len(objects) != 6 {
+				t.Errorf("objects = %v, want the 6 of the tiles", objects)
+			}
+		})
+	}
+}
+
+func TestOCR(t *testing.T) {
+	server := cvtest.NewServer()
+	defer server.Close()
+	client := server.Client()
+
+	result, err := tile.OCR(context.Background(), client, blank(t, 2000, 600), computervision.En, tile.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests := server.RequestCount(cvtest.RouteOCR); requests != 4 {
+		t.Errorf("requests = %v, want the whole image and 3 tiles", requests)
+	}
+	// The line of the first tile is the line of the whole image; the lines
+	// of the last two tiles overlap enough to be the same line.
+	want := []geometry.Rect{{X: 12, Y: 20, W: 468, H: 50}, {X: 836, Y: 20, W: 468, H: 50}}
+	lines := result.Lines()
+	if len(lines) != len(want) {
+		t.Fatalf("lines = %+v, want %v", lines, len(want))
+	}
+	for i, line := range lines {
+		if line.Rectangle != want[i] {
+			t.Errorf("line %v = %v, want %v", i, line.Rectangle, want[i])
+		}
+	}
+	if result.Language != "en" {
+		t.Errorf("language = %q, want the one of the whole image", result.Language)
+	}
+	if result.Width != 2000 || result.Height != 600 {
+		t.Errorf("size = %vx%v, want the size of the whole image", result.Width, result.Height)
+	}
+}